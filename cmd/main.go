@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/NikitaBogoslovskiy/quotes/cmd/routes"
 	"github.com/NikitaBogoslovskiy/quotes/internal/di"
@@ -10,10 +13,23 @@ import (
 )
 
 func main() {
+	config := di.Config{}
+	flag.StringVar(&config.DataDir, "data-dir", "", "directory for persistent storage (in-memory storage if empty)")
+	flag.IntVar(&config.SnapshotEvery, "snapshot-every", 1000, "number of logged changes between snapshots")
+	flag.DurationVar(&config.TrashRetention, "trash-retention", 30*24*time.Hour, "how long deleted quotes are kept in the trash (forever if 0)")
+	flag.DurationVar(&config.IdempotencyTTL, "idempotency-ttl", 24*time.Hour, "how long responses to requests with an Idempotency-Key are kept (keys are ignored if 0)")
+	flag.Parse()
+
+	handlers, err := di.InitializeHandlers(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize handlers: %v", err)
+	}
+
 	router := mux.NewRouter()
 
 	service := routes.NewService(routes.Service{
-		QuotesHandler: di.InitializeQuotesHandler(),
+		QuotesHandler:  handlers.QuotesHandler,
+		AuthorsHandler: handlers.AuthorsHandler,
 	})
 	service.LoadRoutes(router)
 
@@ -6,7 +6,8 @@ import (
 )
 
 type Service struct {
-	QuotesHandler handlers.QuotesHandler
+	QuotesHandler  handlers.QuotesHandler
+	AuthorsHandler handlers.AuthorsHandler
 }
 
 func NewService(service Service) *Service {
@@ -18,5 +19,27 @@ func (s *Service) LoadRoutes(router *mux.Router) {
 	quotes.HandleFunc("", s.QuotesHandler.Create).Methods("POST")
 	quotes.HandleFunc("", s.QuotesHandler.Get).Methods("GET")
 	quotes.HandleFunc("/random", s.QuotesHandler.GetRandom).Methods("GET")
+	quotes.HandleFunc("/search", s.QuotesHandler.Search).Methods("GET")
+	quotes.HandleFunc("/trash", s.QuotesHandler.GetTrash).Methods("GET")
+	quotes.HandleFunc("/trash", s.QuotesHandler.PurgeTrash).Methods("DELETE")
+	quotes.HandleFunc("/trash/{id}", s.QuotesHandler.Purge).Methods("DELETE")
+	quotes.HandleFunc("/{id}", s.QuotesHandler.GetById).Methods("GET")
+	quotes.HandleFunc("/{id}", s.QuotesHandler.Update).Methods("PUT")
+	quotes.HandleFunc("/{id}", s.QuotesHandler.Patch).Methods("PATCH")
 	quotes.HandleFunc("/{id}", s.QuotesHandler.Delete).Methods("DELETE")
+	quotes.HandleFunc("/{id}/restore", s.QuotesHandler.Restore).Methods("POST")
+	quotes.HandleFunc("/{id}/revisions", s.QuotesHandler.GetRevisions).Methods("GET")
+	quotes.HandleFunc("/{id}/revisions/diff", s.QuotesHandler.DiffRevisions).Methods("GET")
+	quotes.HandleFunc("/{id}/revert", s.QuotesHandler.Revert).Methods("POST")
+	quotes.HandleFunc("/{id}/duplicates", s.QuotesHandler.GetDuplicates).Methods("GET")
+
+	authors := router.PathPrefix("/authors").Subrouter()
+	authors.HandleFunc("", s.AuthorsHandler.Get).Methods("GET")
+	authors.HandleFunc("/suggest", s.AuthorsHandler.Suggest).Methods("GET")
+	authors.HandleFunc("/{id}", s.AuthorsHandler.GetById).Methods("GET")
+	authors.HandleFunc("/{id}", s.AuthorsHandler.Patch).Methods("PATCH")
+	authors.HandleFunc("/{id}/quotes", s.AuthorsHandler.GetQuotes).Methods("GET")
+	authors.HandleFunc("/{id}/merge", s.AuthorsHandler.Merge).Methods("POST")
+
+	router.HandleFunc("/tags", s.QuotesHandler.GetTags).Methods("GET")
 }
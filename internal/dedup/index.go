@@ -0,0 +1,184 @@
+// Package dedup finds quotes with nearly the same text. Texts are compared by
+// MinHash signatures of their character shingles, which estimate the Jaccard
+// similarity of the sets of shingles, and candidates are looked up with
+// locality-sensitive hashing over bands of the signatures.
+package dedup
+
+import (
+	"cmp"
+	"hash/fnv"
+	"slices"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+const (
+	shingleSize = 5 // in runes of Quote.Key
+	bands       = 16
+	rows        = 4
+	// with these parameters quotes at least 70% similar become candidates
+	// with probability above 98% and 50% similar ones with about 64%
+	signatureSize = bands * rows
+)
+
+// Signature is a MinHash signature of a text.
+type Signature [signatureSize]uint64
+
+// Sign returns the signature of quote and false if the quote has no letters
+// or digits to compare.
+func Sign(quote types.Quote) (Signature, bool) {
+	key := []rune(string(quote.Key()))
+	if len(key) == 0 {
+		return Signature{}, false
+	}
+
+	var signature Signature
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+
+	// texts shorter than a shingle are a single shingle
+	for start := 0; start == 0 || start+shingleSize <= len(key); start++ {
+		hash := fnv.New64a()
+		hash.Write([]byte(string(key[start:min(start+shingleSize, len(key))])))
+		shingle := hash.Sum64()
+
+		for i := range signature {
+			signature[i] = min(signature[i], mix(shingle+uint64(i)*0x9e3779b97f4a7c15))
+		}
+	}
+
+	return signature, true
+}
+
+// mix is the finalizer of SplitMix64, which turns a shingle hash into one of
+// the independent hash functions of the signature.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Similarity estimates the Jaccard similarity of the texts of two signatures.
+func (s Signature) Similarity(other Signature) float64 {
+	equal := 0
+	for i := range s {
+		if s[i] == other[i] {
+			equal++
+		}
+	}
+
+	return float64(equal) / signatureSize
+}
+
+type bucket struct {
+	band int
+	hash uint64
+}
+
+// Index keeps signatures of quotes. It is not safe for concurrent use, the
+// owner is expected to guard it.
+type Index struct {
+	signatures map[types.Id]Signature
+	// buckets maps bands of signatures to ids of quotes in ascending order
+	buckets map[bucket][]types.Id
+}
+
+// Match is a quote similar to another one.
+type Match struct {
+	Id         types.Id
+	Similarity float64
+}
+
+func NewIndex() *Index {
+	return &Index{
+		signatures: make(map[types.Id]Signature),
+		buckets:    make(map[bucket][]types.Id),
+	}
+}
+
+// Add indexes the quote with specified id. A quote already in the index
+// should be removed first.
+func (idx *Index) Add(id types.Id, quote types.Quote) {
+	signature, ok := Sign(quote)
+	if !ok {
+		return
+	}
+
+	idx.signatures[id] = signature
+	for _, b := range buckets(signature) {
+		pos, _ := slices.BinarySearch(idx.buckets[b], id)
+		idx.buckets[b] = slices.Insert(idx.buckets[b], pos, id)
+	}
+}
+
+func (idx *Index) Remove(id types.Id) {
+	signature, ok := idx.signatures[id]
+	if !ok {
+		return
+	}
+
+	for _, b := range buckets(signature) {
+		ids := idx.buckets[b]
+		pos, found := slices.BinarySearch(ids, id)
+		if !found {
+			continue
+		}
+		if len(ids) == 1 {
+			delete(idx.buckets, b)
+		} else {
+			idx.buckets[b] = slices.Delete(ids, pos, pos+1)
+		}
+	}
+	delete(idx.signatures, id)
+}
+
+// Similar returns other quotes whose estimated similarity to the quote with
+// specified id is at least threshold, the most similar first. Ties are broken
+// by id.
+func (idx *Index) Similar(id types.Id, threshold float64) []Match {
+	signature, ok := idx.signatures[id]
+	if !ok {
+		return []Match{}
+	}
+
+	seen := map[types.Id]bool{id: true}
+	matches := make([]Match, 0)
+	for _, b := range buckets(signature) {
+		for _, other := range idx.buckets[b] {
+			if seen[other] {
+				continue
+			}
+			seen[other] = true
+
+			similarity := signature.Similarity(idx.signatures[other])
+			if similarity >= threshold {
+				matches = append(matches, Match{Id: other, Similarity: similarity})
+			}
+		}
+	}
+
+	slices.SortFunc(matches, func(m1, m2 Match) int {
+		return cmp.Or(cmp.Compare(m2.Similarity, m1.Similarity), cmp.Compare(m1.Id, m2.Id))
+	})
+
+	return matches
+}
+
+func buckets(signature Signature) []bucket {
+	result := make([]bucket, 0, bands)
+	for band := 0; band < bands; band++ {
+		hash := fnv.New64a()
+		for _, value := range signature[band*rows : (band+1)*rows] {
+			for shift := 0; shift < 64; shift += 8 {
+				hash.Write([]byte{byte(value >> shift)})
+			}
+		}
+		result = append(result, bucket{band: band, hash: hash.Sum64()})
+	}
+
+	return result
+}
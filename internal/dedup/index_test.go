@@ -0,0 +1,125 @@
+package dedup
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+func matchIds(matches []Match) []types.Id {
+	ids := make([]types.Id, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.Id)
+	}
+	return ids
+}
+
+func TestSign(t *testing.T) {
+	testCases := []struct {
+		name     string
+		quote1   types.Quote
+		quote2   types.Quote
+		expected float64
+	}{
+		{
+			name:     "Punctuation",
+			quote1:   "Life is really simple, but we insist on making it complicated.",
+			quote2:   "Life is really simple but we insist on making it complicated!",
+			expected: 1,
+		},
+		{
+			name:     "Whitespace",
+			quote1:   "Я мыслю,  следовательно, существую",
+			quote2:   "я мыслю — следовательно существую",
+			expected: 1,
+		},
+		{
+			name:     "ShortText",
+			quote1:   "Да.",
+			quote2:   "да",
+			expected: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			signature1, _ := Sign(testCase.quote1)
+			signature2, _ := Sign(testCase.quote2)
+			similarity := signature1.Similarity(signature2)
+			if similarity != testCase.expected {
+				t.Errorf("signatures have unexpected similarity: got %v want %v", similarity, testCase.expected)
+			}
+		})
+	}
+
+	t.Run("NoWords", func(t *testing.T) {
+		_, ok := Sign("...")
+		if ok {
+			t.Errorf("quote without words was signed")
+		}
+	})
+}
+
+func TestSimilar(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(1, "Life is really simple, but we insist on making it complicated.")
+	idx.Add(2, "Life is really simple, but men insist on making it complicated.")
+	idx.Add(3, "It does not matter how slowly you go as long as you do not stop.")
+	idx.Add(4, "Life is very simple, but we insist on making it complicated")
+	idx.Add(5, "...")
+
+	testCases := []struct {
+		name      string
+		id        types.Id
+		threshold float64
+		expected  []types.Id
+	}{
+		{
+			name:      "NearDuplicates",
+			id:        1,
+			threshold: 0.6,
+			expected:  []types.Id{2, 4},
+		},
+		{
+			name:      "HighThreshold",
+			id:        1,
+			threshold: 0.95,
+			expected:  []types.Id{},
+		},
+		{
+			name:      "Unrelated",
+			id:        3,
+			threshold: 0.5,
+			expected:  []types.Id{},
+		},
+		{
+			name:      "NotIndexed",
+			id:        5,
+			threshold: 0.5,
+			expected:  []types.Id{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			matches := idx.Similar(testCase.id, testCase.threshold)
+			ids := matchIds(matches)
+			slices.Sort(ids)
+			if !slices.Equal(ids, testCase.expected) {
+				t.Errorf("index returned unexpected matches: got %v want %v", matches, testCase.expected)
+			}
+		})
+	}
+
+	t.Run("Removed", func(t *testing.T) {
+		idx.Remove(2)
+		ids := matchIds(idx.Similar(1, 0.6))
+		if !slices.Equal(ids, []types.Id{4}) {
+			t.Errorf("index returned unexpected matches: got %v want %v", ids, []types.Id{4})
+		}
+		if len(idx.signatures) != 3 {
+			t.Errorf("index kept unexpected number of signatures: got %v want %v", len(idx.signatures), 3)
+		}
+	})
+}
@@ -1,15 +1,57 @@
 package di
 
 import (
+	"time"
+
 	"github.com/NikitaBogoslovskiy/quotes/internal/handlers"
 	"github.com/NikitaBogoslovskiy/quotes/internal/services"
 	"github.com/NikitaBogoslovskiy/quotes/internal/stores"
 )
 
-func InitializeQuotesHandler() handlers.QuotesHandler {
-	quotesStore := stores.NewQuotesStore()
-	quotesService := services.NewQuotesService(quotesStore)
-	quotesHandler := handlers.NewQuotesHandler(quotesService)
+type Config struct {
+	DataDir       string // quotes are kept only in memory if empty
+	SnapshotEvery int
+	// TrashRetention is how long deleted quotes are kept in the trash,
+	// they are kept until purged manually if it is zero.
+	TrashRetention time.Duration
+	// IdempotencyTTL is how long responses to requests with an idempotency
+	// key are kept, idempotency keys are ignored if it is zero.
+	IdempotencyTTL time.Duration
+}
+
+type Handlers struct {
+	QuotesHandler  handlers.QuotesHandler
+	AuthorsHandler handlers.AuthorsHandler
+}
+
+func InitializeHandlers(config Config) (Handlers, error) {
+	store, err := initializeStore(config)
+	if err != nil {
+		return Handlers{}, err
+	}
+	quotesService := services.NewQuotesService(store)
+	authorsService := services.NewAuthorsService(store, quotesService)
+
+	if config.TrashRetention > 0 {
+		go services.CollectTrash(quotesService, config.TrashRetention, min(config.TrashRetention, time.Hour), nil)
+	}
+
+	quotesOptions := make([]handlers.QuotesHandlerOption, 0, 1)
+	if config.IdempotencyTTL > 0 {
+		idempotencyService := services.NewIdempotencyService(stores.NewIdempotencyStore(config.IdempotencyTTL))
+		quotesOptions = append(quotesOptions, handlers.WithIdempotency(idempotencyService))
+	}
+
+	return Handlers{
+		QuotesHandler:  handlers.NewQuotesHandler(quotesService, quotesOptions...),
+		AuthorsHandler: handlers.NewAuthorsHandler(authorsService),
+	}, nil
+}
+
+func initializeStore(config Config) (stores.Store, error) {
+	if config.DataDir == "" {
+		return stores.NewQuotesStore(), nil
+	}
 
-	return quotesHandler
+	return stores.NewFileQuotesStore(config.DataDir, config.SnapshotEvery)
 }
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/services"
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+type AuthorsHandler interface {
+	Get(w http.ResponseWriter, r *http.Request)
+	Suggest(w http.ResponseWriter, r *http.Request)
+	GetById(w http.ResponseWriter, r *http.Request)
+	GetQuotes(w http.ResponseWriter, r *http.Request)
+	Patch(w http.ResponseWriter, r *http.Request)
+	Merge(w http.ResponseWriter, r *http.Request)
+}
+
+type authorsHandler struct {
+	authorsService services.AuthorsService
+}
+
+func NewAuthorsHandler(authorsService services.AuthorsService) AuthorsHandler {
+	return &authorsHandler{authorsService: authorsService}
+}
+
+func (ah *authorsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	authors, err := ah.authorsService.Get()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.GetAuthorsResponse{Ok: true, Authors: authors})
+}
+
+func (ah *authorsHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	urlParams := r.URL.Query()
+	request := types.SuggestAuthorsRequest{
+		Prefix: types.Author(urlParams.Get("prefix")),
+		Match:  types.AuthorMatch(urlParams.Get("match")),
+	}
+
+	if limit := urlParams.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 {
+			writeError(w, r, errInvalidLimit)
+			return
+		}
+		request.Limit = value
+	}
+
+	authors, err := ah.authorsService.Suggest(request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.SuggestAuthorsResponse{Ok: true, Authors: authors})
+}
+
+func (ah *authorsHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	author, err := ah.authorsService.GetById(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.GetAuthorResponse{Ok: true, Author: author})
+}
+
+func (ah *authorsHandler) GetQuotes(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request, err := parseGetQuotesRequest(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	page, err := ah.authorsService.GetQuotes(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.GetQuotesResponse{Ok: true, Quotes: page.Quotes, NextCursor: page.NextCursor})
+}
+
+func (ah *authorsHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request := types.PatchAuthorRequest{}
+	err = readRequest(r, &request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	author, err := ah.authorsService.Patch(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.UpdateAuthorResponse{Ok: true, Author: author})
+}
+
+func (ah *authorsHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request := types.MergeAuthorsRequest{}
+	err = readRequest(r, &request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request.Actor = r.Header.Get(actorHeader)
+
+	author, err := ah.authorsService.Merge(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.UpdateAuthorResponse{Ok: true, Author: author})
+}
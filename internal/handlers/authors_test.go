@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/services"
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+	"github.com/gorilla/mux"
+)
+
+type authorsServiceStub struct{}
+
+func (as *authorsServiceStub) Get() ([]types.AuthorData, error) {
+	return []types.AuthorData{{Id: 1, Name: "Author", QuoteCount: 2}}, nil
+}
+
+func (as *authorsServiceStub) Suggest(request types.SuggestAuthorsRequest) ([]types.AuthorData, error) {
+	if request.Prefix == "" {
+		return nil, services.ErrInvalidInput
+	}
+
+	return []types.AuthorData{{Id: 1, Name: "Author", QuoteCount: 2}}, nil
+}
+
+func (as *authorsServiceStub) GetById(id types.Id) (types.AuthorData, error) {
+	if id != 1 {
+		return types.AuthorData{}, services.ErrAuthorNotFound
+	}
+
+	return types.AuthorData{Id: id, Name: "Author", Aliases: []types.Author{"Alias"}, BirthYear: -551, QuoteCount: 2}, nil
+}
+
+func (as *authorsServiceStub) GetQuotes(id types.Id, request types.GetQuotesRequest) (types.QuotesPage, error) {
+	if id != 1 {
+		return types.QuotesPage{}, services.ErrAuthorNotFound
+	}
+
+	quote := types.QuoteData{Id: 1, Author: "Author", Quote: "Quote", AuthorId: id, CreatedAt: stubTime, UpdatedAt: stubTime}
+	return types.QuotesPage{Quotes: []types.QuoteData{quote}}, nil
+}
+
+func (as *authorsServiceStub) Patch(id types.Id, request types.PatchAuthorRequest) (types.AuthorData, error) {
+	author, err := as.GetById(id)
+	if err != nil {
+		return types.AuthorData{}, err
+	}
+	if request.Name != nil && *request.Name == "Taken" {
+		return types.AuthorData{}, services.ErrAuthorConflict
+	}
+
+	return request.Apply(author), nil
+}
+
+func (as *authorsServiceStub) Merge(id types.Id, request types.MergeAuthorsRequest) (types.AuthorData, error) {
+	if request.Into == 0 {
+		return types.AuthorData{}, services.ErrInvalidInput
+	}
+	if id != 2 || request.Into != 1 {
+		return types.AuthorData{}, services.ErrAuthorNotFound
+	}
+
+	return types.AuthorData{Id: 1, Name: "Author", Aliases: []types.Author{"Other"}, QuoteCount: 3}, nil
+}
+
+func TestGetAuthors(t *testing.T) {
+	authorsHandler := NewAuthorsHandler(&authorsServiceStub{})
+
+	req, err := http.NewRequest("GET", "/authors", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(authorsHandler.Get)
+	handler.ServeHTTP(rr, req)
+
+	expected := `{"ok":true,"authors":[{"id":1,"name":"Author","quote_count":2}]}`
+	if rr.Code != http.StatusOK {
+		t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if rr.Body.String() != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), expected)
+	}
+}
+
+func TestGetAuthorById(t *testing.T) {
+	authorsHandler := NewAuthorsHandler(&authorsServiceStub{})
+
+	testCases := []struct {
+		name           string
+		authorId       string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "StringId",
+			authorId:       "abc",
+			expected:       `{"ok":false,"message":"id should be a non-negative number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UnknownId",
+			authorId:       "2",
+			expected:       `{"ok":false,"message":"no author with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectId",
+			authorId:       "1",
+			expected:       `{"ok":true,"author":{"id":1,"name":"Author","aliases":["Alias"],"birth_year":-551,"quote_count":2}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/authors", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.authorId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(authorsHandler.GetById)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetAuthorQuotes(t *testing.T) {
+	authorsHandler := NewAuthorsHandler(&authorsServiceStub{})
+
+	testCases := []struct {
+		name           string
+		authorId       string
+		query          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "UnknownId",
+			authorId:       "2",
+			expected:       `{"ok":false,"message":"no author with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "IncorrectLimit",
+			authorId:       "1",
+			query:          "?limit=abc",
+			expected:       `{"ok":false,"message":"limit should be a positive number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CorrectId",
+			authorId:       "1",
+			expected:       `{"ok":true,"quotes":[{"id":1,"author":"Author","quote":"Quote","author_id":1,"created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/authors/quotes"+tc.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.authorId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(authorsHandler.GetQuotes)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestPatchAuthor(t *testing.T) {
+	authorsHandler := NewAuthorsHandler(&authorsServiceStub{})
+
+	testCases := []struct {
+		name           string
+		authorId       string
+		input          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "NullName",
+			authorId:       "1",
+			input:          `{"name":null}`,
+			expected:       `{"ok":false,"message":"incorrect request format","code":"malformed_request"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "NameTaken",
+			authorId:       "1",
+			input:          `{"name":"Taken"}`,
+			expected:       `{"ok":false,"message":"name is already used by another author","code":"conflict"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "NullFields",
+			authorId:       "1",
+			input:          `{"aliases":null,"birth_year":null,"bio":"Bio"}`,
+			expected:       `{"ok":true,"author":{"id":1,"name":"Author","bio":"Bio","quote_count":2}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("PATCH", "/authors", bytes.NewBuffer([]byte(tc.input)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.authorId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(authorsHandler.Patch)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestMergeAuthors(t *testing.T) {
+	authorsHandler := NewAuthorsHandler(&authorsServiceStub{})
+
+	testCases := []struct {
+		name           string
+		authorId       string
+		input          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "IncorrectBrackets",
+			authorId:       "2",
+			input:          `{`,
+			expected:       `{"ok":false,"message":"incorrect request format","code":"malformed_request"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UnknownId",
+			authorId:       "3",
+			input:          `{"into":1}`,
+			expected:       `{"ok":false,"message":"no author with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectInput",
+			authorId:       "2",
+			input:          `{"into":1}`,
+			expected:       `{"ok":true,"author":{"id":1,"name":"Author","aliases":["Other"],"quote_count":3}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/authors/merge", bytes.NewBuffer([]byte(tc.input)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.authorId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(authorsHandler.Merge)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestSuggestAuthors(t *testing.T) {
+	authorsHandler := NewAuthorsHandler(&authorsServiceStub{})
+
+	testCases := []struct {
+		name           string
+		query          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "EmptyPrefix",
+			query:          "",
+			expected:       `{"ok":false,"message":"invalid input","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "IncorrectLimit",
+			query:          "?prefix=au&limit=0",
+			expected:       `{"ok":false,"message":"limit should be a positive number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CorrectPrefix",
+			query:          "?prefix=au&limit=5",
+			expected:       `{"ok":true,"authors":[{"id":1,"name":"Author","quote_count":2}]}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/authors/suggest"+tc.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(authorsHandler.Suggest)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/services"
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+// quoteETag returns the strong entity tag of a quote, which is its version.
+func quoteETag(quote types.QuoteData) string {
+	return strconv.Quote(strconv.FormatUint(quote.Version, 10))
+}
+
+// parseIfMatch returns versions of a quote expected by the If-Match header,
+// which may list several entity tags, or no versions if any version is fine.
+// Weak tags never match, since If-Match requires strong comparison.
+func parseIfMatch(r *http.Request) (types.Versions, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	versions := make(types.Versions, 0)
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		opaque, weak := strings.CutPrefix(tag, "W/")
+		unquoted, err := strconv.Unquote(opaque)
+		if err != nil || !strings.HasPrefix(opaque, `"`) {
+			return nil, errInvalidIfMatch
+		}
+
+		// other tags cannot be versions of the quote
+		version, err := strconv.ParseUint(unquoted, 10, 64)
+		if weak || err != nil || version == 0 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return nil, services.ErrVersionMismatch
+	}
+
+	return versions, nil
+}
+
+// etagMatches compares etag with tags listed in an If-None-Match header using
+// weak comparison.
+func etagMatches(header string, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeCacheable answers with 304 Not Modified and no body if the response
+// has not changed since the client got it. An empty etag is computed from the
+// response body.
+func writeCacheable(w http.ResponseWriter, r *http.Request, etag string, response any) {
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if etag == "" {
+		hash := fnv.New64a()
+		hash.Write(responseBody)
+		etag = fmt.Sprintf(`W/"%x"`, hash.Sum64())
+	}
+	w.Header().Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBody)
+}
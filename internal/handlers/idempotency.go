@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/services"
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	rr.body.Write(data)
+	return rr.ResponseWriter.Write(data)
+}
+
+// serveIdempotent serves a request having an idempotency key with handler
+// only once and replays the saved response for retries. Requests with the
+// same key must have the same method, path and body. Client errors are saved
+// and replayed like successful responses, while responses with 5xx statuses
+// are not saved, so such requests can be retried with the same key.
+func serveIdempotent(idempotencyService services.IdempotencyService, key types.IdempotencyKey,
+	w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(requestBody))
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(requestBody)
+	fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+	saved, err := idempotencyService.Begin(key, fingerprint)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if saved != nil {
+		w.Header().Set("Content-Type", saved.ContentType)
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(saved.Status)
+		w.Write(saved.Body)
+		return
+	}
+
+	completed := false
+	defer func() {
+		if !completed {
+			idempotencyService.Release(key)
+		}
+	}()
+
+	recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	handler(recorder, r)
+
+	if recorder.status < http.StatusInternalServerError {
+		idempotencyService.Complete(key, types.StoredResponse{
+			Status:      recorder.status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		completed = true
+	}
+}
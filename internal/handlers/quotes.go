@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/NikitaBogoslovskiy/quotes/internal/services"
 	"github.com/NikitaBogoslovskiy/quotes/internal/types"
@@ -14,97 +15,484 @@ import (
 type QuotesHandler interface {
 	Create(w http.ResponseWriter, r *http.Request)
 	Get(w http.ResponseWriter, r *http.Request)
+	Search(w http.ResponseWriter, r *http.Request)
+	GetTags(w http.ResponseWriter, r *http.Request)
 	GetRandom(w http.ResponseWriter, r *http.Request)
+	GetById(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Patch(w http.ResponseWriter, r *http.Request)
 	Delete(w http.ResponseWriter, r *http.Request)
+	GetTrash(w http.ResponseWriter, r *http.Request)
+	Restore(w http.ResponseWriter, r *http.Request)
+	Purge(w http.ResponseWriter, r *http.Request)
+	PurgeTrash(w http.ResponseWriter, r *http.Request)
+	GetRevisions(w http.ResponseWriter, r *http.Request)
+	DiffRevisions(w http.ResponseWriter, r *http.Request)
+	Revert(w http.ResponseWriter, r *http.Request)
+	GetDuplicates(w http.ResponseWriter, r *http.Request)
 }
 
+// actorHeader names whoever makes a change, it is saved in quote revisions.
+const actorHeader = "X-Actor"
+
 type quotesHandler struct {
-	quotesService services.QuotesService
+	quotesService      services.QuotesService
+	idempotencyService services.IdempotencyService
 }
 
-func NewQuotesHandler(quotesService services.QuotesService) QuotesHandler {
-	return &quotesHandler{quotesService: quotesService}
+type QuotesHandlerOption func(qh *quotesHandler)
+
+// WithIdempotency makes Create honor the Idempotency-Key header, which is
+// ignored otherwise.
+func WithIdempotency(idempotencyService services.IdempotencyService) QuotesHandlerOption {
+	return func(qh *quotesHandler) {
+		qh.idempotencyService = idempotencyService
+	}
+}
+
+func NewQuotesHandler(quotesService services.QuotesService, options ...QuotesHandlerOption) QuotesHandler {
+	qh := &quotesHandler{quotesService: quotesService}
+	for _, option := range options {
+		option(qh)
+	}
+
+	return qh
 }
 
 func (qh *quotesHandler) Create(w http.ResponseWriter, r *http.Request) {
-	requestBody, err := io.ReadAll(r.Body)
-	if err != nil {
-		errorResponse, _ := json.Marshal(types.CreateQuoteResponse{Ok: false, Message: "internal server error"})
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(errorResponse)
+	key := r.Header.Get(idempotencyKeyHeader)
+	if qh.idempotencyService == nil || key == "" {
+		qh.create(w, r)
 		return
 	}
 
+	serveIdempotent(qh.idempotencyService, types.IdempotencyKey(key), w, r, qh.create)
+}
+
+func (qh *quotesHandler) create(w http.ResponseWriter, r *http.Request) {
 	request := types.CreateQuoteRequest{}
-	err = json.Unmarshal(requestBody, &request)
+	err := readRequest(r, &request)
 	if err != nil {
-		errorResponse, _ := json.Marshal(types.CreateQuoteResponse{Ok: false, Message: "incorrect request format"})
-		w.Write(errorResponse)
+		writeError(w, r, err)
 		return
 	}
+	request.Actor = r.Header.Get(actorHeader)
 
-	response := qh.quotesService.Create(request)
-
-	responseBody, err := json.Marshal(response)
+	id, err := qh.quotesService.Create(request)
 	if err != nil {
-		errorResponse, _ := json.Marshal(types.CreateQuoteResponse{Ok: false, Message: "internal server error"})
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(errorResponse)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Write(responseBody)
+	writeJSON(w, types.CreateQuoteResponse{Ok: true, Id: id})
 }
 
 func (qh *quotesHandler) Get(w http.ResponseWriter, r *http.Request) {
-	author := types.Author(r.URL.Query().Get("author"))
+	request, err := parseGetQuotesRequest(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	page, err := qh.quotesService.Get(request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeCacheable(w, r, "", types.GetQuotesResponse{Ok: true, Quotes: page.Quotes, NextCursor: page.NextCursor})
+}
+
+func (qh *quotesHandler) Search(w http.ResponseWriter, r *http.Request) {
+	urlParams := r.URL.Query()
+	request := types.SearchQuotesRequest{Query: urlParams.Get("q")}
+
+	if limit := urlParams.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 {
+			writeError(w, r, errInvalidLimit)
+			return
+		}
+		request.Limit = value
+	}
+
+	results, err := qh.quotesService.Search(request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	response := qh.quotesService.Get(author)
+	writeJSON(w, types.SearchQuotesResponse{Ok: true, Results: results})
+}
 
-	responseBody, err := json.Marshal(response)
+func (qh *quotesHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := qh.quotesService.GetTags()
 	if err != nil {
-		errorResponse, _ := json.Marshal(types.GetQuotesResponse{Ok: false, Message: "internal server error"})
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(errorResponse)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Write(responseBody)
+	writeJSON(w, types.GetTagsResponse{Ok: true, Tags: tags})
 }
 
 func (qh *quotesHandler) GetRandom(w http.ResponseWriter, r *http.Request) {
-	response := qh.quotesService.GetRandom()
+	quote, err := qh.quotesService.GetRandom()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.GetRandomQuoteResponse{Ok: true, Quote: quote})
+}
+
+func (qh *quotesHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	quote, err := qh.quotesService.GetById(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeCacheable(w, r, quoteETag(quote), types.GetQuoteResponse{Ok: true, Quote: quote})
+}
+
+func (qh *quotesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request := types.UpdateQuoteRequest{}
+	err = readRequest(r, &request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	request.Actor = r.Header.Get(actorHeader)
+	request.Versions, err = parseIfMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	quote, err := qh.quotesService.Update(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(quote))
+	writeJSON(w, types.UpdateQuoteResponse{Ok: true, Quote: quote})
+}
+
+func (qh *quotesHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	responseBody, err := json.Marshal(response)
+	request := types.PatchQuoteRequest{}
+	err = readRequest(r, &request)
 	if err != nil {
-		errorResponse, _ := json.Marshal(types.GetRandomQuoteResponse{Ok: false, Message: "internal server error"})
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(errorResponse)
+		writeError(w, r, err)
+		return
+	}
+	request.Actor = r.Header.Get(actorHeader)
+	request.Versions, err = parseIfMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	quote, err := qh.quotesService.Patch(id, request)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.Write(responseBody)
+	w.Header().Set("ETag", quoteETag(quote))
+	writeJSON(w, types.UpdateQuoteResponse{Ok: true, Quote: quote})
 }
 
 func (qh *quotesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request := types.DeleteQuoteRequest{Actor: r.Header.Get(actorHeader)}
+	request.Versions, err = parseIfMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	err = qh.quotesService.Delete(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.DeleteQuoteResponse{Ok: true})
+}
+
+func (qh *quotesHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	quotes, err := qh.quotesService.GetTrash()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.GetTrashResponse{Ok: true, Quotes: quotes})
+}
+
+func (qh *quotesHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request := types.RestoreQuoteRequest{Actor: r.Header.Get(actorHeader)}
+	quote, err := qh.quotesService.Restore(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(quote))
+	writeJSON(w, types.RestoreQuoteResponse{Ok: true, Quote: quote})
+}
+
+func (qh *quotesHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	err = qh.quotesService.Purge(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.PurgeTrashResponse{Ok: true, Purged: 1})
+}
+
+func (qh *quotesHandler) PurgeTrash(w http.ResponseWriter, r *http.Request) {
+	deletedBefore, err := parseTimestamp(r.URL.Query().Get("deleted_before"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	purged, err := qh.quotesService.PurgeTrash(deletedBefore)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.PurgeTrashResponse{Ok: true, Purged: purged})
+}
+
+func (qh *quotesHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	revisions, err := qh.quotesService.GetRevisions(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.GetRevisionsResponse{Ok: true, Revisions: revisions})
+}
+
+func (qh *quotesHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	urlParams := r.URL.Query()
+	request := types.DiffRevisionsRequest{}
+	request.From, err = parseRevision(urlParams.Get("from"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	request.To, err = parseRevision(urlParams.Get("to"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	diff, err := qh.quotesService.DiffRevisions(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.DiffRevisionsResponse{Ok: true, Diff: diff})
+}
+
+func (qh *quotesHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request := types.RevertQuoteRequest{Actor: r.Header.Get(actorHeader)}
+	request.To, err = parseRevision(r.URL.Query().Get("to"))
+	if err != nil || request.To == 0 {
+		writeError(w, r, errInvalidRevision)
+		return
+	}
+
+	quote, err := qh.quotesService.Revert(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(quote))
+	writeJSON(w, types.UpdateQuoteResponse{Ok: true, Quote: quote})
+}
+
+func (qh *quotesHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	request := types.GetDuplicatesRequest{}
+	threshold := r.URL.Query().Get("threshold")
+	if threshold != "" {
+		request.Threshold, err = strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			writeError(w, r, errInvalidThreshold)
+			return
+		}
+	}
+
+	duplicates, err := qh.quotesService.GetDuplicates(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, types.GetDuplicatesResponse{Ok: true, Duplicates: duplicates})
+}
+
+func readRequest(r *http.Request, request any) error {
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(requestBody, request)
+	if err != nil {
+		return errMalformedRequest
+	}
+
+	return nil
+}
+
+func parseId(r *http.Request) (types.Id, error) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		errorResponse, _ := json.Marshal(types.DeleteQuoteResponse{Ok: false, Message: "id should be a non-negative number"})
-		w.Write(errorResponse)
-		return
+		return 0, errInvalidId
 	}
 
-	response := qh.quotesService.Delete(types.Id(id))
+	return types.Id(id), nil
+}
 
-	responseBody, err := json.Marshal(response)
+// parseGetQuotesRequest reads filters, sort order and pagination of a quotes
+// listing from URL parameters.
+func parseGetQuotesRequest(r *http.Request) (types.GetQuotesRequest, error) {
+	urlParams := r.URL.Query()
+	request := types.GetQuotesRequest{
+		Author:      types.Author(urlParams.Get("author")),
+		AuthorMatch: types.AuthorMatch(urlParams.Get("author_match")),
+		TagMode:     urlParams.Get("tag_mode"),
+		Attribution: types.Attribution(urlParams.Get("attribution")),
+		Sort:        urlParams.Get("sort"),
+		Cursor:      urlParams.Get("cursor"),
+	}
+	for _, tag := range urlParams["tag"] {
+		request.Tags = append(request.Tags, types.Tag(tag))
+	}
+
+	if limit := urlParams.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 {
+			return request, errInvalidLimit
+		}
+		request.Limit = value
+	}
+
+	if verified := urlParams.Get("verified"); verified != "" {
+		value, err := strconv.ParseBool(verified)
+		if err != nil {
+			return request, errInvalidVerified
+		}
+		request.Verified = &value
+	}
+
+	var err error
+	request.CreatedAfter, err = parseTimestamp(urlParams.Get("created_after"))
 	if err != nil {
-		errorResponse, _ := json.Marshal(types.DeleteQuoteResponse{Ok: false, Message: "internal server error"})
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(errorResponse)
-		return
+		return request, err
+	}
+	request.CreatedBefore, err = parseTimestamp(urlParams.Get("created_before"))
+	if err != nil {
+		return request, err
+	}
+
+	return request, nil
+}
+
+// parseRevision returns zero for an empty value.
+func parseRevision(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision <= 0 {
+		return 0, errInvalidRevision
+	}
+
+	return revision, nil
+}
+
+// parseTimestamp returns zero time for an empty value.
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errInvalidTimestamp
 	}
 
-	w.Write(responseBody)
+	return timestamp, nil
 }
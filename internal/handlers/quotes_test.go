@@ -5,63 +5,230 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/NikitaBogoslovskiy/quotes/internal/services"
+	"github.com/NikitaBogoslovskiy/quotes/internal/stores"
 	"github.com/NikitaBogoslovskiy/quotes/internal/types"
 	"github.com/gorilla/mux"
 )
 
+var stubTime = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
 type quotesServiceStub struct{}
 
-func (qs *quotesServiceStub) Create(request types.CreateQuoteRequest) types.CreateQuoteResponse {
-	return types.CreateQuoteResponse{Ok: true, Id: 1}
+func (qs *quotesServiceStub) Create(request types.CreateQuoteRequest) (types.Id, error) {
+	if request.Author == "" {
+		return 0, services.ErrInvalidInput
+	}
+	if request.Author == "Full" {
+		return 0, services.ErrCapacityExceeded
+	}
+	if request.Quote == "Duplicate" {
+		return 0, services.DuplicateQuoteError{Id: 7}
+	}
+
+	return 1, nil
+}
+
+func (qs *quotesServiceStub) Get(request types.GetQuotesRequest) (types.QuotesPage, error) {
+	if request.Limit > 0 {
+		return types.QuotesPage{Quotes: make([]types.QuoteData, 0), NextCursor: "next"}, nil
+	}
+
+	return types.QuotesPage{Quotes: make([]types.QuoteData, 0)}, nil
+}
+
+func (qs *quotesServiceStub) Search(request types.SearchQuotesRequest) ([]types.SearchHit, error) {
+	if request.Query == "" {
+		return nil, services.ErrInvalidInput
+	}
+
+	quote := types.QuoteData{Id: 1, Author: "Author", Quote: "Quote", CreatedAt: stubTime, UpdatedAt: stubTime}
+	return []types.SearchHit{{Quote: quote, Score: 1.5, Snippet: "<mark>Quote</mark>"}}, nil
+}
+
+func (qs *quotesServiceStub) GetTags() ([]types.TagCount, error) {
+	return []types.TagCount{{Tag: "life", Count: 2}, {Tag: "love", Count: 1}}, nil
+}
+
+func (qs *quotesServiceStub) GetRandom() (types.QuoteData, error) {
+	return types.QuoteData{Id: 1, Author: "Author", Quote: "Quote", CreatedAt: stubTime, UpdatedAt: stubTime}, nil
+}
+
+func (qs *quotesServiceStub) GetById(id types.Id) (types.QuoteData, error) {
+	if id != 1 {
+		return types.QuoteData{}, services.ErrQuoteNotFound
+	}
+
+	return types.QuoteData{Id: id, Author: "Author", Quote: "Quote", CreatedAt: stubTime, UpdatedAt: stubTime, Version: 3}, nil
+}
+
+func (qs *quotesServiceStub) Update(id types.Id, request types.UpdateQuoteRequest) (types.QuoteData, error) {
+	if id != 1 {
+		return types.QuoteData{}, services.ErrQuoteNotFound
+	}
+	if !request.Versions.Match(3) {
+		return types.QuoteData{}, services.ErrVersionMismatch
+	}
+
+	return types.QuoteData{Id: id, Author: request.Author, Quote: request.Quote, CreatedAt: stubTime, UpdatedAt: stubTime, Version: 4}, nil
+}
+
+func (qs *quotesServiceStub) Patch(id types.Id, request types.PatchQuoteRequest) (types.QuoteData, error) {
+	if id != 1 {
+		return types.QuoteData{}, services.ErrQuoteNotFound
+	}
+
+	quote := types.QuoteData{Id: id, Author: "Author", Quote: "Quote", CreatedAt: stubTime, UpdatedAt: stubTime}
+	if request.Author != nil {
+		quote.Author = *request.Author
+	}
+	if request.Quote != nil {
+		quote.Quote = *request.Quote
+	}
+
+	return quote, nil
+}
+
+func (qs *quotesServiceStub) Delete(id types.Id, request types.DeleteQuoteRequest) error {
+	if id != 1 {
+		return services.ErrQuoteNotFound
+	}
+	if !request.Versions.Match(3) {
+		return services.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+func (qs *quotesServiceStub) GetTrash() ([]types.QuoteData, error) {
+	deletedAt := stubTime
+	return []types.QuoteData{{Id: 2, Author: "Author", Quote: "Quote", CreatedAt: stubTime, UpdatedAt: stubTime, DeletedAt: &deletedAt}}, nil
+}
+
+func (qs *quotesServiceStub) Restore(id types.Id, request types.RestoreQuoteRequest) (types.QuoteData, error) {
+	if id != 2 {
+		return types.QuoteData{}, services.ErrQuoteNotFound
+	}
+
+	return types.QuoteData{Id: id, Author: "Author", Quote: "Quote", CreatedAt: stubTime, UpdatedAt: stubTime, UpdatedBy: request.Actor}, nil
+}
+
+func (qs *quotesServiceStub) Purge(id types.Id) error {
+	if id != 2 {
+		return services.ErrQuoteNotFound
+	}
+
+	return nil
 }
 
-func (qs *quotesServiceStub) Get(author types.Author) types.GetQuotesResponse {
-	return types.GetQuotesResponse{Ok: true, Quotes: make([]types.QuoteData, 0)}
+func (qs *quotesServiceStub) GetRevisions(id types.Id) ([]types.Revision, error) {
+	if id != 1 {
+		return nil, services.ErrQuoteNotFound
+	}
+
+	return []types.Revision{{QuoteId: id, Number: 1, Author: "Author", Quote: "Quote", Actor: "alice", CreatedAt: stubTime}}, nil
+}
+
+func (qs *quotesServiceStub) DiffRevisions(id types.Id, request types.DiffRevisionsRequest) (types.RevisionDiff, error) {
+	if request.From > 2 || request.To > 2 {
+		return types.RevisionDiff{}, services.ErrRevisionNotFound
+	}
+
+	return types.RevisionDiff{QuoteId: id, From: 1, To: 2, Changes: []types.FieldChange{{Field: "quote", From: types.Quote("Quote1"), To: types.Quote("Quote2")}}}, nil
+}
+
+func (qs *quotesServiceStub) Revert(id types.Id, request types.RevertQuoteRequest) (types.QuoteData, error) {
+	if request.To > 2 {
+		return types.QuoteData{}, services.ErrRevisionNotFound
+	}
+
+	return types.QuoteData{Id: id, Author: "Author", Quote: "Quote", CreatedAt: stubTime, UpdatedAt: stubTime, UpdatedBy: request.Actor}, nil
 }
 
-func (qs *quotesServiceStub) GetRandom() types.GetRandomQuoteResponse {
-	return types.GetRandomQuoteResponse{Ok: true, Quote: types.QuoteData{}}
+func (qs *quotesServiceStub) PurgeTrash(deletedBefore time.Time) (int, error) {
+	if deletedBefore.IsZero() {
+		return 3, nil
+	}
+
+	return 1, nil
 }
 
-func (qs *quotesServiceStub) Delete(id types.Id) types.DeleteQuoteResponse {
-	return types.DeleteQuoteResponse{Ok: true}
+func (qs *quotesServiceStub) GetDuplicates(id types.Id, request types.GetDuplicatesRequest) ([]types.Duplicate, error) {
+	if request.Threshold > 1 {
+		return nil, services.ErrInvalidInput
+	}
+	if id != 1 {
+		return nil, services.ErrQuoteNotFound
+	}
+	if request.Threshold > 0.9 {
+		return []types.Duplicate{}, nil
+	}
+
+	return []types.Duplicate{{Quote: types.QuoteData{Id: 2, Author: "Author", Quote: "Quote!"}, Similarity: 0.75}}, nil
 }
 
 func TestCreate(t *testing.T) {
 	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
 
 	testCases := []struct {
-		name     string
-		input    string
-		expected string
+		name           string
+		input          string
+		expected       string
+		expectedStatus int
 	}{
 		{
-			name:     "EmptyInput",
-			input:    ``,
-			expected: `{"ok":false,"message":"incorrect request format"}`,
+			name:           "EmptyInput",
+			input:          ``,
+			expected:       `{"ok":false,"message":"incorrect request format","code":"malformed_request"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "IncorrectBrackets",
+			input:          `{`,
+			expected:       `{"ok":false,"message":"incorrect request format","code":"malformed_request"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "IncorrectQuotes",
+			input:          `{"author":"Author,"quote":"Quote"}`,
+			expected:       `{"ok":false,"message":"incorrect request format","code":"malformed_request"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "IncorrectDataType",
+			input:          `{"author":"Author","quote":42}`,
+			expected:       `{"ok":false,"message":"incorrect request format","code":"malformed_request"}`,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:     "IncorrectBrackets",
-			input:    `{`,
-			expected: `{"ok":false,"message":"incorrect request format"}`,
+			name:           "InvalidInput",
+			input:          `{"quote":"Quote"}`,
+			expected:       `{"ok":false,"message":"invalid input","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:     "IncorrectQuotes",
-			input:    `{"author":"Author,"quote":"Quote"}`,
-			expected: `{"ok":false,"message":"incorrect request format"}`,
+			name:           "CapacityExceeded",
+			input:          `{"author":"Full","quote":"Quote"}`,
+			expected:       `{"ok":false,"message":"space limit exceeded","code":"capacity_exceeded"}`,
+			expectedStatus: http.StatusConflict,
 		},
 		{
-			name:     "IncorrectDataType",
-			input:    `{"author":"Author","quote":42}`,
-			expected: `{"ok":false,"message":"incorrect request format"}`,
+			name:           "Duplicate",
+			input:          `{"author":"Author","quote":"Duplicate"}`,
+			expected:       `{"ok":false,"message":"author already has the same quote","code":"duplicate","existing_id":7}`,
+			expectedStatus: http.StatusConflict,
 		},
 		{
-			name:     "CorrectInput",
-			input:    `{"author":"Author","quote":"Quote"}`,
-			expected: `{"ok":true,"id":1}`,
+			name:           "CorrectInput",
+			input:          `{"author":"Author","quote":"Quote"}`,
+			expected:       `{"ok":true,"id":1}`,
+			expectedStatus: http.StatusOK,
 		},
 	}
 
@@ -76,9 +243,116 @@ func TestCreate(t *testing.T) {
 			handler := http.HandlerFunc(quotesHandler.Create)
 			handler.ServeHTTP(rr, req)
 
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestCreateIdempotent(t *testing.T) {
+	idempotencyService := services.NewIdempotencyService(stores.NewIdempotencyStore(time.Hour))
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{}, WithIdempotency(idempotencyService))
+
+	testCases := []struct {
+		name             string
+		key              string
+		input            string
+		expected         string
+		expectedStatus   int
+		expectedReplayed string
+	}{
+		{
+			name:           "FirstRequest",
+			key:            "key1",
+			input:          `{"author":"Author","quote":"Quote"}`,
+			expected:       `{"ok":true,"id":1}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:             "Retry",
+			key:              "key1",
+			input:            `{"author":"Author","quote":"Quote"}`,
+			expected:         `{"ok":true,"id":1}`,
+			expectedStatus:   http.StatusOK,
+			expectedReplayed: "true",
+		},
+		{
+			name:           "OtherPayload",
+			key:            "key1",
+			input:          `{"author":"Author","quote":"Other"}`,
+			expected:       `{"ok":false,"message":"idempotency key has already been used for another request","code":"idempotency_key_reused"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "FailedRequest",
+			key:            "key2",
+			input:          `{"author":"Full","quote":"Quote"}`,
+			expected:       `{"ok":false,"message":"space limit exceeded","code":"capacity_exceeded"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:             "FailedRequestRetry",
+			key:              "key2",
+			input:            `{"author":"Full","quote":"Quote"}`,
+			expected:         `{"ok":false,"message":"space limit exceeded","code":"capacity_exceeded"}`,
+			expectedStatus:   http.StatusConflict,
+			expectedReplayed: "true",
+		},
+		{
+			name:           "InvalidRequest",
+			key:            "key3",
+			input:          `{"quote":"Quote"}`,
+			expected:       `{"ok":false,"message":"invalid input","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:             "InvalidRequestRetry",
+			key:              "key3",
+			input:            `{"quote":"Quote"}`,
+			expected:         `{"ok":false,"message":"invalid input","code":"invalid_input"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedReplayed: "true",
+		},
+		{
+			name:           "LongKey",
+			key:            strings.Repeat("k", 256),
+			input:          `{"author":"Author","quote":"Quote"}`,
+			expected:       `{"ok":false,"message":"idempotency key cannot be longer than 255 bytes","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "NoKey",
+			input:          `{"author":"Author","quote":"Quote"}`,
+			expected:       `{"ok":true,"id":1}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/quotes", strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Idempotency-Key", tc.key)
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.Create)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
 			if rr.Body.String() != tc.expected {
 				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
 			}
+			if replayed := rr.Header().Get("Idempotent-Replayed"); replayed != tc.expectedReplayed {
+				t.Errorf("handler returned unexpected replay header: got %v want %v", replayed, tc.expectedReplayed)
+			}
 		})
 	}
 }
@@ -111,6 +385,46 @@ func TestGet(t *testing.T) {
 			urlParams: map[string]string{"author": "Author", "other": "field"},
 			expected:  `{"ok":true,"quotes":[]}`,
 		},
+		{
+			name:      "TagURLParams",
+			urlParams: map[string]string{"tag": "life", "tag_mode": "any"},
+			expected:  `{"ok":true,"quotes":[]}`,
+		},
+		{
+			name:      "VerifiedURLParam",
+			urlParams: map[string]string{"verified": "true"},
+			expected:  `{"ok":true,"quotes":[]}`,
+		},
+		{
+			name:      "IncorrectVerified",
+			urlParams: map[string]string{"verified": "maybe"},
+			expected:  `{"ok":false,"message":"verified should be either true or false","code":"invalid_input"}`,
+		},
+		{
+			name:      "LimitURLParam",
+			urlParams: map[string]string{"limit": "10"},
+			expected:  `{"ok":true,"quotes":[],"next_cursor":"next"}`,
+		},
+		{
+			name:      "CreatedAfterURLParam",
+			urlParams: map[string]string{"created_after": "2025-03-01T12:00:00Z"},
+			expected:  `{"ok":true,"quotes":[]}`,
+		},
+		{
+			name:      "IncorrectCreatedBefore",
+			urlParams: map[string]string{"created_before": "yesterday"},
+			expected:  `{"ok":false,"message":"timestamps should be in RFC 3339 format","code":"invalid_input"}`,
+		},
+		{
+			name:      "IncorrectLimit",
+			urlParams: map[string]string{"limit": "abc"},
+			expected:  `{"ok":false,"message":"limit should be a positive number","code":"invalid_input"}`,
+		},
+		{
+			name:      "NegativeLimit",
+			urlParams: map[string]string{"limit": "-1"},
+			expected:  `{"ok":false,"message":"limit should be a positive number","code":"invalid_input"}`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -143,6 +457,74 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestSearch(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		url            string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "NoQuery",
+			url:            "/quotes/search",
+			expected:       `{"ok":false,"message":"invalid input","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "IncorrectLimit",
+			url:            "/quotes/search?q=quote&limit=0",
+			expected:       `{"ok":false,"message":"limit should be a positive number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CorrectQuery",
+			url:            "/quotes/search?q=quote&limit=5",
+			expected:       `{"ok":true,"results":[{"quote":{"id":1,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z"},"score":1.5,"snippet":"\u003cmark\u003eQuote\u003c/mark\u003e"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tc.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.Search)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	req, err := http.NewRequest("GET", "/tags", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(quotesHandler.GetTags)
+	handler.ServeHTTP(rr, req)
+
+	expected := `{"ok":true,"tags":[{"tag":"life","count":2},{"tag":"love","count":1}]}`
+	if rr.Body.String() != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), expected)
+	}
+}
+
 func TestGetRandom(t *testing.T) {
 	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
 
@@ -152,7 +534,7 @@ func TestGetRandom(t *testing.T) {
 	}{
 		{
 			name:     "GetRandom",
-			expected: `{"ok":true,"quote":{}}`,
+			expected: `{"ok":true,"quote":{"id":1,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z"}}`,
 		},
 	}
 
@@ -174,51 +556,925 @@ func TestGetRandom(t *testing.T) {
 	}
 }
 
-func TestDelete(t *testing.T) {
+func TestGetById(t *testing.T) {
 	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
 
 	testCases := []struct {
-		name     string
-		quoteId  string
-		expected string
+		name           string
+		quoteId        string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "StringId",
+			quoteId:        "abc",
+			expected:       `{"ok":false,"message":"id should be a non-negative number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UnknownId",
+			quoteId:        "2",
+			expected:       `{"ok":false,"message":"no quote with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectId",
+			quoteId:        "1",
+			expected:       `{"ok":true,"quote":{"id":1,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z","version":3}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/quotes", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.quoteId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.GetById)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		quoteId        string
+		input          string
+		expected       string
+		expectedStatus int
 	}{
 		{
-			name:     "EmptyId",
-			quoteId:  "",
-			expected: `{"ok":false,"message":"id should be a non-negative number"}`,
+			name:           "StringId",
+			quoteId:        "abc",
+			input:          `{"author":"NewAuthor","quote":"NewQuote"}`,
+			expected:       `{"ok":false,"message":"id should be a non-negative number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:     "StringId",
-			quoteId:  "abc",
-			expected: `{"ok":false,"message":"id should be a non-negative number"}`,
+			name:           "IncorrectBrackets",
+			quoteId:        "1",
+			input:          `{`,
+			expected:       `{"ok":false,"message":"incorrect request format","code":"malformed_request"}`,
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:     "NegativeId",
-			quoteId:  "-2",
-			expected: `{"ok":false,"message":"id should be a non-negative number"}`,
+			name:           "UnknownId",
+			quoteId:        "2",
+			input:          `{"author":"NewAuthor","quote":"NewQuote"}`,
+			expected:       `{"ok":false,"message":"no quote with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
 		},
 		{
-			name:     "CorrectId",
-			quoteId:  "1",
-			expected: `{"ok":true}`,
+			name:           "CorrectInput",
+			quoteId:        "1",
+			input:          `{"author":"NewAuthor","quote":"NewQuote"}`,
+			expected:       `{"ok":true,"quote":{"id":1,"author":"NewAuthor","quote":"NewQuote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z","version":4}}`,
+			expectedStatus: http.StatusOK,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			req, err := http.NewRequest("DELETE", "/quotes", nil)
+			req, err := http.NewRequest("PUT", "/quotes", bytes.NewBuffer([]byte(tc.input)))
 			if err != nil {
 				t.Fatal(err)
 			}
 			req = mux.SetURLVars(req, map[string]string{"id": tc.quoteId})
 
 			rr := httptest.NewRecorder()
-			handler := http.HandlerFunc(quotesHandler.Delete)
+			handler := http.HandlerFunc(quotesHandler.Update)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		quoteId        string
+		input          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "NullAuthor",
+			quoteId:        "1",
+			input:          `{"author":null}`,
+			expected:       `{"ok":false,"message":"incorrect request format","code":"malformed_request"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UnknownId",
+			quoteId:        "2",
+			input:          `{"quote":"NewQuote"}`,
+			expected:       `{"ok":false,"message":"no quote with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "EmptyPatch",
+			quoteId:        "1",
+			input:          `{}`,
+			expected:       `{"ok":true,"quote":{"id":1,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "QuoteOnly",
+			quoteId:        "1",
+			input:          `{"quote":"NewQuote"}`,
+			expected:       `{"ok":true,"quote":{"id":1,"author":"Author","quote":"NewQuote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z"}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("PATCH", "/quotes", bytes.NewBuffer([]byte(tc.input)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.quoteId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.Patch)
 			handler.ServeHTTP(rr, req)
 
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
 			if rr.Body.String() != tc.expected {
 				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
 			}
 		})
 	}
 }
+
+func TestDelete(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		quoteId        string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "EmptyId",
+			quoteId:        "",
+			expected:       `{"ok":false,"message":"id should be a non-negative number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "StringId",
+			quoteId:        "abc",
+			expected:       `{"ok":false,"message":"id should be a non-negative number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "NegativeId",
+			quoteId:        "-2",
+			expected:       `{"ok":false,"message":"id should be a non-negative number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UnknownId",
+			quoteId:        "2",
+			expected:       `{"ok":false,"message":"no quote with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectId",
+			quoteId:        "1",
+			expected:       `{"ok":true}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("DELETE", "/quotes", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.quoteId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.Delete)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetTrash(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	req, err := http.NewRequest("GET", "/quotes/trash", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(quotesHandler.GetTrash)
+	handler.ServeHTTP(rr, req)
+
+	expected := `{"ok":true,"quotes":[{"id":2,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z","deleted_at":"2025-03-01T12:00:00Z"}]}`
+	if rr.Code != http.StatusOK {
+		t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if rr.Body.String() != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), expected)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		quoteId        string
+		actor          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "StringId",
+			quoteId:        "abc",
+			expected:       `{"ok":false,"message":"id should be a non-negative number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "NotInTrash",
+			quoteId:        "1",
+			expected:       `{"ok":false,"message":"no quote with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectId",
+			quoteId:        "2",
+			actor:          "alice",
+			expected:       `{"ok":true,"quote":{"id":2,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z","updated_by":"alice"}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/quotes/restore", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-Actor", tc.actor)
+			req = mux.SetURLVars(req, map[string]string{"id": tc.quoteId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.Restore)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		quoteId        string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "NotInTrash",
+			quoteId:        "1",
+			expected:       `{"ok":false,"message":"no quote with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectId",
+			quoteId:        "2",
+			expected:       `{"ok":true,"purged":1}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("DELETE", "/quotes/trash", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.quoteId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.Purge)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		query          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "All",
+			query:          "",
+			expected:       `{"ok":true,"purged":3}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "DeletedBefore",
+			query:          "?deleted_before=2025-03-01T12:00:00Z",
+			expected:       `{"ok":true,"purged":1}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "InvalidTimestamp",
+			query:          "?deleted_before=yesterday",
+			expected:       `{"ok":false,"message":"timestamps should be in RFC 3339 format","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("DELETE", "/quotes/trash"+tc.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.PurgeTrash)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetRevisions(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		quoteId        string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "UnknownId",
+			quoteId:        "2",
+			expected:       `{"ok":false,"message":"no quote with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectId",
+			quoteId:        "1",
+			expected:       `{"ok":true,"revisions":[{"quote_id":1,"number":1,"author":"Author","quote":"Quote","actor":"alice","created_at":"2025-03-01T12:00:00Z"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/quotes/revisions", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.quoteId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.GetRevisions)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestDiffRevisions(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		query          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "InvalidRevision",
+			query:          "?from=first",
+			expected:       `{"ok":false,"message":"revision should be a positive number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UnknownRevision",
+			query:          "?from=1&to=5",
+			expected:       `{"ok":false,"message":"no revision with specified number","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectRevisions",
+			query:          "?from=1&to=2",
+			expected:       `{"ok":true,"diff":{"quote_id":1,"from":1,"to":2,"changes":[{"field":"quote","from":"Quote1","to":"Quote2"}]}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/quotes/revisions/diff"+tc.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.DiffRevisions)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestRevert(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		query          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "MissingRevision",
+			query:          "",
+			expected:       `{"ok":false,"message":"revision should be a positive number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "ZeroRevision",
+			query:          "?to=0",
+			expected:       `{"ok":false,"message":"revision should be a positive number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UnknownRevision",
+			query:          "?to=3",
+			expected:       `{"ok":false,"message":"no revision with specified number","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "CorrectRevision",
+			query:          "?to=1",
+			expected:       `{"ok":true,"quote":{"id":1,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z","updated_by":"alice"}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/quotes/revert"+tc.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-Actor", "alice")
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.Revert)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		method         string
+		handler        http.HandlerFunc
+		header         string
+		value          string
+		body           string
+		expectedStatus int
+		expectedETag   string
+		expected       string
+	}{
+		{
+			name:           "GetNotModified",
+			method:         "GET",
+			handler:        quotesHandler.GetById,
+			header:         "If-None-Match",
+			value:          `"3"`,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:           "GetNotModifiedAnyOf",
+			method:         "GET",
+			handler:        quotesHandler.GetById,
+			header:         "If-None-Match",
+			value:          `"2", W/"3"`,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:           "GetModified",
+			method:         "GET",
+			handler:        quotesHandler.GetById,
+			header:         "If-None-Match",
+			value:          `"2"`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+			expected:       `{"ok":true,"quote":{"id":1,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z","version":3}}`,
+		},
+		{
+			name:           "UpdateStale",
+			method:         "PUT",
+			handler:        quotesHandler.Update,
+			header:         "If-Match",
+			value:          `"2"`,
+			body:           `{"author":"Author","quote":"Quote"}`,
+			expectedStatus: http.StatusPreconditionFailed,
+			expected:       `{"ok":false,"message":"quote has been changed since specified version","code":"version_mismatch"}`,
+		},
+		{
+			name:           "UpdateWeakTag",
+			method:         "PUT",
+			handler:        quotesHandler.Update,
+			header:         "If-Match",
+			value:          `W/"3"`,
+			body:           `{"author":"Author","quote":"Quote"}`,
+			expectedStatus: http.StatusPreconditionFailed,
+			expected:       `{"ok":false,"message":"quote has been changed since specified version","code":"version_mismatch"}`,
+		},
+		{
+			name:           "UpdateInvalidTag",
+			method:         "PUT",
+			handler:        quotesHandler.Update,
+			header:         "If-Match",
+			value:          `3`,
+			body:           `{"author":"Author","quote":"Quote"}`,
+			expectedStatus: http.StatusBadRequest,
+			expected:       `{"ok":false,"message":"If-Match should be * or a list of entity tags of the quote","code":"invalid_input"}`,
+		},
+		{
+			name:           "UpdateCurrent",
+			method:         "PUT",
+			handler:        quotesHandler.Update,
+			header:         "If-Match",
+			value:          `"3"`,
+			body:           `{"author":"Author","quote":"Quote"}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+			expected:       `{"ok":true,"quote":{"id":1,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z","version":4}}`,
+		},
+		{
+			name:           "UpdateAnyOf",
+			method:         "PUT",
+			handler:        quotesHandler.Update,
+			header:         "If-Match",
+			value:          `"2", "3"`,
+			body:           `{"author":"Author","quote":"Quote"}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+			expected:       `{"ok":true,"quote":{"id":1,"author":"Author","quote":"Quote","created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z","version":4}}`,
+		},
+		{
+			name:           "UpdateNoneOf",
+			method:         "PUT",
+			handler:        quotesHandler.Update,
+			header:         "If-Match",
+			value:          `"1","2", W/"3"`,
+			body:           `{"author":"Author","quote":"Quote"}`,
+			expectedStatus: http.StatusPreconditionFailed,
+			expected:       `{"ok":false,"message":"quote has been changed since specified version","code":"version_mismatch"}`,
+		},
+		{
+			name:           "UpdateInvalidTagInList",
+			method:         "PUT",
+			handler:        quotesHandler.Update,
+			header:         "If-Match",
+			value:          `"3", 4`,
+			body:           `{"author":"Author","quote":"Quote"}`,
+			expectedStatus: http.StatusBadRequest,
+			expected:       `{"ok":false,"message":"If-Match should be * or a list of entity tags of the quote","code":"invalid_input"}`,
+		},
+		{
+			name:           "DeleteStale",
+			method:         "DELETE",
+			handler:        quotesHandler.Delete,
+			header:         "If-Match",
+			value:          `"2"`,
+			expectedStatus: http.StatusPreconditionFailed,
+			expected:       `{"ok":false,"message":"quote has been changed since specified version","code":"version_mismatch"}`,
+		},
+		{
+			name:           "DeleteAnyOf",
+			method:         "DELETE",
+			handler:        quotesHandler.Delete,
+			header:         "If-Match",
+			value:          `"3", "5"`,
+			expectedStatus: http.StatusOK,
+			expected:       `{"ok":true}`,
+		},
+		{
+			name:           "DeleteAnyVersion",
+			method:         "DELETE",
+			handler:        quotesHandler.Delete,
+			header:         "If-Match",
+			value:          `*`,
+			expectedStatus: http.StatusOK,
+			expected:       `{"ok":true}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, "/quotes", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set(tc.header, tc.value)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			rr := httptest.NewRecorder()
+			tc.handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if etag := rr.Header().Get("ETag"); etag != tc.expectedETag {
+				t.Errorf("handler returned unexpected etag: got %v want %v", etag, tc.expectedETag)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetNotModified(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	get := func(url string, etag string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("If-None-Match", etag)
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(quotesHandler.Get)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	etag := get("/quotes", "").Header().Get("ETag")
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("handler returned unexpected etag: %v", etag)
+	}
+
+	t.Run("SameListing", func(t *testing.T) {
+		rr := get("/quotes", etag)
+		if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+			t.Errorf("handler returned unexpected response: %v %v", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("OtherListing", func(t *testing.T) {
+		rr := get("/quotes?limit=1", etag)
+		if rr.Code != http.StatusOK {
+			t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, http.StatusOK)
+		}
+	})
+}
+
+func TestGetDuplicates(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	testCases := []struct {
+		name           string
+		quoteId        string
+		threshold      string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			name:           "InvalidThreshold",
+			quoteId:        "1",
+			threshold:      "high",
+			expected:       `{"ok":false,"message":"threshold should be a number","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "OutOfRangeThreshold",
+			quoteId:        "1",
+			threshold:      "2",
+			expected:       `{"ok":false,"message":"invalid input","code":"invalid_input"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "UnknownId",
+			quoteId:        "2",
+			expected:       `{"ok":false,"message":"no quote with specified id","code":"not_found"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "NoDuplicates",
+			quoteId:        "1",
+			threshold:      "0.95",
+			expected:       `{"ok":true,"duplicates":[]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Duplicates",
+			quoteId:        "1",
+			expected:       `{"ok":true,"duplicates":[{"quote":{"id":2,"author":"Author","quote":"Quote!","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"},"similarity":0.75}]}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/quotes/duplicates?threshold="+tc.threshold, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tc.quoteId})
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(quotesHandler.GetDuplicates)
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestLegacyQuoteIfMatch(t *testing.T) {
+	dir := t.TempDir()
+	// a quote logged before versions were introduced
+	wal := `{"curr_id":1,"put":[{"id":1,"author":"Author","quote":"Quote"}]}` + "\n"
+	err := os.WriteFile(filepath.Join(dir, "quotes.wal"), []byte(wal), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotesStore, err := stores.NewFileQuotesStore(dir, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotesHandler := NewQuotesHandler(services.NewQuotesService(quotesStore))
+
+	etag := ""
+	testCases := []struct {
+		name           string
+		method         string
+		handler        http.HandlerFunc
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "Get",
+			method:         "GET",
+			handler:        quotesHandler.GetById,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Update",
+			method:         "PUT",
+			handler:        quotesHandler.Update,
+			body:           `{"author":"Author","quote":"New quote"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Delete",
+			method:         "DELETE",
+			handler:        quotesHandler.Delete,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	// every request sends back the entity tag returned by the previous one
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, "/quotes", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("If-Match", etag)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			rr := httptest.NewRecorder()
+			tc.handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned unexpected status: got %v want %v, body %v", rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			etag = rr.Header().Get("ETag")
+		})
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	quotesHandler := NewQuotesHandler(&quotesServiceStub{})
+
+	req, err := http.NewRequest("GET", "/quotes", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/problem+json")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(quotesHandler.GetById)
+	handler.ServeHTTP(rr, req)
+
+	expected := `{"type":"about:blank","title":"Not Found","status":404,"detail":"no quote with specified id","code":"not_found"}`
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("handler returned unexpected status: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Errorf("handler returned unexpected content type: got %v want %v", contentType, "application/problem+json")
+	}
+	if rr.Body.String() != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), expected)
+	}
+}
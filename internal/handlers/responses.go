@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/services"
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+var (
+	errMalformedRequest = errors.New("incorrect request format")
+	errInvalidId        = errors.New("id should be a non-negative number")
+	errInvalidLimit     = errors.New("limit should be a positive number")
+	errInvalidTimestamp = errors.New("timestamps should be in RFC 3339 format")
+	errInvalidVerified  = errors.New("verified should be either true or false")
+	errInvalidRevision  = errors.New("revision should be a positive number")
+	errInvalidIfMatch   = errors.New("If-Match should be * or a list of entity tags of the quote")
+	errInvalidThreshold = errors.New("threshold should be a number")
+	errInternal         = errors.New("internal server error")
+)
+
+const problemContentType = "application/problem+json"
+
+func errorStatus(err error) (int, types.ErrorCode) {
+	switch {
+	case errors.Is(err, errMalformedRequest):
+		return http.StatusBadRequest, types.ErrorCodeMalformedRequest
+	case errors.Is(err, errInvalidId), errors.Is(err, errInvalidLimit),
+		errors.Is(err, errInvalidTimestamp), errors.Is(err, errInvalidVerified),
+		errors.Is(err, errInvalidRevision), errors.Is(err, errInvalidIfMatch),
+		errors.Is(err, errInvalidThreshold), errors.Is(err, services.ErrInvalidInput):
+		return http.StatusBadRequest, types.ErrorCodeInvalidInput
+	case errors.Is(err, services.ErrQuoteNotFound), errors.Is(err, services.ErrNoQuotes),
+		errors.Is(err, services.ErrAuthorNotFound), errors.Is(err, services.ErrRevisionNotFound):
+		return http.StatusNotFound, types.ErrorCodeNotFound
+	case errors.Is(err, services.ErrAuthorConflict):
+		return http.StatusConflict, types.ErrorCodeConflict
+	case errors.Is(err, services.ErrDuplicateQuote):
+		return http.StatusConflict, types.ErrorCodeDuplicate
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity, types.ErrorCodeIdempotencyKeyReused
+	case errors.Is(err, services.ErrRequestInProgress):
+		return http.StatusConflict, types.ErrorCodeRequestInProgress
+	case errors.Is(err, services.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, types.ErrorCodeVersionMismatch
+	case errors.Is(err, services.ErrCapacityExceeded):
+		return http.StatusConflict, types.ErrorCodeCapacityExceeded
+	default:
+		return http.StatusInternalServerError, types.ErrorCodeInternal
+	}
+}
+
+// writeError answers with an RFC 7807 problem if the client accepts it and
+// with an ErrorResponse otherwise. Messages of unexpected errors are hidden.
+// The id of a duplicated quote is returned along with the error.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code := errorStatus(err)
+
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		message = errInternal.Error()
+	}
+
+	var duplicate services.DuplicateQuoteError
+	errors.As(err, &duplicate)
+
+	if strings.Contains(r.Header.Get("Accept"), problemContentType) {
+		problem := types.Problem{
+			Type:       "about:blank",
+			Title:      http.StatusText(status),
+			Status:     status,
+			Detail:     message,
+			Code:       code,
+			ExistingId: duplicate.Id,
+		}
+		writeBody(w, problemContentType, status, problem)
+		return
+	}
+
+	writeBody(w, "application/json", status, types.ErrorResponse{Ok: false, Message: message, Code: code, ExistingId: duplicate.Id})
+}
+
+func writeJSON(w http.ResponseWriter, response any) {
+	writeBody(w, "application/json", http.StatusOK, response)
+}
+
+func writeBody(w http.ResponseWriter, contentType string, status int, body any) {
+	responseBody, err := json.Marshal(body)
+	if err != nil {
+		status = http.StatusInternalServerError
+		responseBody, _ = json.Marshal(types.ErrorResponse{Ok: false, Message: errInternal.Error(), Code: types.ErrorCodeInternal})
+		contentType = "application/json"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(responseBody)
+}
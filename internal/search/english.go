@@ -0,0 +1,301 @@
+package search
+
+import "strings"
+
+// English stemming follows the Snowball (Porter2) English stemmer, see
+// https://snowballstem.org/algorithms/english/stemmer.html
+
+var englishExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// englishInvariants are left as is after step 1a.
+var englishInvariants = map[string]bool{
+	"inning": true, "outing": true, "canning": true, "herring": true,
+	"earring": true, "proceed": true, "exceed": true, "succeed": true,
+}
+
+var englishStep2 = []struct{ suffix, replacement string }{
+	{"ization", "ize"}, {"ational", "ate"}, {"fulness", "ful"}, {"ousness", "ous"},
+	{"iveness", "ive"}, {"tional", "tion"}, {"biliti", "ble"}, {"lessli", "less"},
+	{"entli", "ent"}, {"ation", "ate"}, {"alism", "al"}, {"aliti", "al"},
+	{"ousli", "ous"}, {"iviti", "ive"}, {"fulli", "ful"}, {"enci", "ence"},
+	{"anci", "ance"}, {"abli", "able"}, {"izer", "ize"}, {"ator", "ate"},
+	{"alli", "al"}, {"bli", "ble"}, {"ogi", "og"}, {"li", ""},
+}
+
+var englishStep3 = []struct{ suffix, replacement string }{
+	{"ational", "ate"}, {"tional", "tion"}, {"alize", "al"}, {"icate", "ic"},
+	{"iciti", "ic"}, {"ative", ""}, {"ical", "ic"}, {"ness", ""}, {"ful", ""},
+}
+
+var englishStep4 = []string{
+	"ement", "ance", "ence", "able", "ible", "ment", "ant", "ent", "ism", "ate",
+	"iti", "ous", "ive", "ize", "ion", "al", "er", "ic",
+}
+
+func isEnglishVowel(r byte) bool {
+	return strings.IndexByte("aeiouy", r) >= 0
+}
+
+// englishWord is a word being stemmed with regions R1 and R2 of the
+// algorithm. Initial y and y after a vowel are kept as Y to be treated as
+// consonants.
+type englishWord struct {
+	s      []byte
+	r1, r2 int
+}
+
+func (w *englishWord) vowel(i int) bool {
+	return isEnglishVowel(w.s[i])
+}
+
+func (w *englishWord) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(w.s), suffix)
+}
+
+func (w *englishWord) replace(suffix, replacement string) {
+	w.s = append(w.s[:len(w.s)-len(suffix)], replacement...)
+}
+
+// shortSyllableAt reports whether the word ends with a short syllable at i,
+// i.e. the syllable ends at i.
+func (w *englishWord) shortSyllableAt(i int) bool {
+	if i == 1 {
+		return w.vowel(0) && !w.vowel(1)
+	}
+	if i < 2 {
+		return false
+	}
+
+	c := w.s[i]
+	return !w.vowel(i-2) && w.vowel(i-1) && !w.vowel(i) && c != 'w' && c != 'x' && c != 'Y'
+}
+
+func (w *englishWord) short() bool {
+	return w.r1 >= len(w.s) && w.shortSyllableAt(len(w.s)-1)
+}
+
+// region returns the position after the first non-vowel following a vowel
+// starting from start.
+func (w *englishWord) region(start int) int {
+	for i := start + 1; i < len(w.s); i++ {
+		if !w.vowel(i) && w.vowel(i-1) {
+			return i + 1
+		}
+	}
+
+	return len(w.s)
+}
+
+// isASCIIWord reports whether word consists of lowercase latin letters and
+// digits only.
+func isASCIIWord(word string) bool {
+	for i := 0; i < len(word); i++ {
+		c := word[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func stemEnglish(word string) string {
+	if len(word) <= 2 || !isASCIIWord(word) {
+		return word
+	}
+	if stem, ok := englishExceptions[word]; ok {
+		return stem
+	}
+
+	w := &englishWord{s: []byte(word)}
+	for i := range w.s {
+		if w.s[i] == 'y' && (i == 0 || isEnglishVowel(w.s[i-1])) {
+			w.s[i] = 'Y'
+		}
+	}
+
+	w.r1 = w.region(0)
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(word, prefix) {
+			w.r1 = len(prefix)
+		}
+	}
+	w.r2 = w.region(w.r1)
+
+	w.step1a()
+	if englishInvariants[string(w.s)] {
+		return string(w.s)
+	}
+	w.step1b()
+	w.step1c()
+	w.step2()
+	w.step3()
+	w.step4()
+	w.step5()
+
+	return strings.ReplaceAll(string(w.s), "Y", "y")
+}
+
+func (w *englishWord) step1a() {
+	switch {
+	case w.hasSuffix("sses"):
+		w.replace("sses", "ss")
+	case w.hasSuffix("ied"), w.hasSuffix("ies"):
+		suffix := string(w.s[len(w.s)-3:])
+		if len(w.s) > 4 {
+			w.replace(suffix, "i")
+		} else {
+			w.replace(suffix, "ie")
+		}
+	case w.hasSuffix("us"), w.hasSuffix("ss"):
+	case w.hasSuffix("s"):
+		// s is deleted if a vowel is found before the letter preceding it
+		for i := 0; i < len(w.s)-2; i++ {
+			if w.vowel(i) {
+				w.replace("s", "")
+				return
+			}
+		}
+	}
+}
+
+func (w *englishWord) step1b() {
+	for _, suffix := range []string{"eedly", "eed"} {
+		if w.hasSuffix(suffix) {
+			if len(w.s)-len(suffix) >= w.r1 {
+				w.replace(suffix, "ee")
+			}
+			return
+		}
+	}
+
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed"} {
+		if !w.hasSuffix(suffix) {
+			continue
+		}
+
+		stem := w.s[:len(w.s)-len(suffix)]
+		if strings.IndexAny(string(stem), "aeiouy") < 0 {
+			return
+		}
+		w.s = stem
+
+		switch {
+		case w.hasSuffix("at"), w.hasSuffix("bl"), w.hasSuffix("iz"):
+			w.s = append(w.s, 'e')
+		case w.endsWithDouble():
+			w.s = w.s[:len(w.s)-1]
+		case w.short():
+			w.s = append(w.s, 'e')
+		}
+		return
+	}
+}
+
+func (w *englishWord) endsWithDouble() bool {
+	for _, double := range []string{"bb", "dd", "ff", "gg", "mm", "nn", "pp", "rr", "tt"} {
+		if w.hasSuffix(double) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *englishWord) step1c() {
+	n := len(w.s)
+	if n > 2 && (w.s[n-1] == 'y' || w.s[n-1] == 'Y') && !w.vowel(n-2) {
+		w.s[n-1] = 'i'
+	}
+}
+
+func (w *englishWord) step2() {
+	for _, rule := range englishStep2 {
+		if !w.hasSuffix(rule.suffix) {
+			continue
+		}
+
+		start := len(w.s) - len(rule.suffix)
+		if start < w.r1 {
+			return
+		}
+		switch rule.suffix {
+		case "ogi":
+			if start == 0 || w.s[start-1] != 'l' {
+				return
+			}
+		case "li":
+			if start == 0 || strings.IndexByte("cdeghkmnrt", w.s[start-1]) < 0 {
+				return
+			}
+		}
+		w.replace(rule.suffix, rule.replacement)
+		return
+	}
+}
+
+func (w *englishWord) step3() {
+	for _, rule := range englishStep3 {
+		if !w.hasSuffix(rule.suffix) {
+			continue
+		}
+
+		start := len(w.s) - len(rule.suffix)
+		if start < w.r1 || (rule.suffix == "ative" && start < w.r2) {
+			return
+		}
+		w.replace(rule.suffix, rule.replacement)
+		return
+	}
+}
+
+func (w *englishWord) step4() {
+	for _, suffix := range englishStep4 {
+		if !w.hasSuffix(suffix) {
+			continue
+		}
+
+		start := len(w.s) - len(suffix)
+		if start < w.r2 {
+			return
+		}
+		if suffix == "ion" && (start == 0 || (w.s[start-1] != 's' && w.s[start-1] != 't')) {
+			return
+		}
+		w.replace(suffix, "")
+		return
+	}
+}
+
+func (w *englishWord) step5() {
+	n := len(w.s)
+	switch {
+	case w.hasSuffix("e"):
+		if n-1 >= w.r2 || (n-1 >= w.r1 && !w.shortSyllableAt(n-2)) {
+			w.s = w.s[:n-1]
+		}
+	case w.hasSuffix("ll"):
+		if n-1 >= w.r2 {
+			w.s = w.s[:n-1]
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+// BM25 parameters commonly used for short documents.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Index is an inverted index of quote texts. It is not safe for concurrent
+// use, the owner is expected to guard it.
+type Index struct {
+	// postings maps a term to positions of the term in each quote
+	postings map[string]map[types.Id][]int
+	// terms holds all indexed terms in ascending order for prefix lookups
+	terms       []string
+	docs        map[types.Id]document
+	totalLength int
+}
+
+type document struct {
+	terms  []string // distinct terms of the quote
+	length int
+}
+
+// Hit is a quote matching a query. Terms are indexed terms of the quote which
+// matched the query, they are used to highlight the quote.
+type Hit struct {
+	Id    types.Id
+	Score float64
+	Terms []string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[types.Id][]int),
+		docs:     make(map[types.Id]document),
+	}
+}
+
+// Add indexes text of the quote with specified id. A quote already in the
+// index should be removed first. Both words and their stems are indexed at the
+// same positions, so prefix queries work with whole words.
+func (idx *Index) Add(id types.Id, text string) {
+	tokens := analyze(text)
+
+	doc := document{length: len(tokens)}
+	for pos, t := range tokens {
+		if idx.addPosting(t.term, id, pos) {
+			doc.terms = append(doc.terms, t.term)
+		}
+		if t.stem != t.term && idx.addPosting(t.stem, id, pos) {
+			doc.terms = append(doc.terms, t.stem)
+		}
+	}
+
+	idx.docs[id] = doc
+	idx.totalLength += doc.length
+}
+
+// addPosting records that term appears in the quote at pos and reports whether
+// it is the first appearance of term in the quote.
+func (idx *Index) addPosting(term string, id types.Id, pos int) bool {
+	postings, ok := idx.postings[term]
+	if !ok {
+		postings = make(map[types.Id][]int)
+		idx.postings[term] = postings
+		i, _ := slices.BinarySearch(idx.terms, term)
+		idx.terms = slices.Insert(idx.terms, i, term)
+	}
+
+	first := len(postings[id]) == 0
+	postings[id] = append(postings[id], pos)
+	return first
+}
+
+func (idx *Index) Remove(id types.Id) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		postings := idx.postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(idx.postings, term)
+			i, _ := slices.BinarySearch(idx.terms, term)
+			idx.terms = slices.Delete(idx.terms, i, i+1)
+		}
+	}
+
+	delete(idx.docs, id)
+	idx.totalLength -= doc.length
+}
+
+// Search returns at most limit quotes matching query, the most relevant
+// first. Quotes are ranked by BM25, ties are broken by id.
+func (idx *Index) Search(query Query, limit int) []Hit {
+	var candidates map[types.Id]struct{}
+	expanded := make([][][]string, 0, len(query.clauses))
+	for _, c := range query.clauses {
+		slots := idx.expand(c)
+		expanded = append(expanded, slots)
+
+		matching := idx.matching(slots)
+		if candidates != nil {
+			for id := range candidates {
+				if _, ok := matching[id]; !ok {
+					delete(candidates, id)
+				}
+			}
+		} else {
+			candidates = matching
+		}
+		if len(candidates) == 0 {
+			return []Hit{}
+		}
+	}
+
+	hits := make([]Hit, 0, len(candidates))
+	for id := range candidates {
+		hits = append(hits, idx.score(id, expanded))
+	}
+	slices.SortFunc(hits, func(h1, h2 Hit) int {
+		switch {
+		case h1.Score > h2.Score:
+			return -1
+		case h1.Score < h2.Score:
+			return 1
+		default:
+			return cmp.Compare(h1.Id, h2.Id)
+		}
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits
+}
+
+// expand returns indexed terms which can appear at each position of clause.
+func (idx *Index) expand(c clause) [][]string {
+	slots := make([][]string, 0, len(c.terms))
+	for i, term := range c.terms {
+		if !c.prefix || i != len(c.terms)-1 {
+			slot := []string{term}
+			if c.stems[i] != term {
+				slot = append(slot, c.stems[i])
+			}
+			slots = append(slots, slot)
+			continue
+		}
+
+		start, _ := slices.BinarySearch(idx.terms, term)
+		end := start
+		for end < len(idx.terms) && strings.HasPrefix(idx.terms[end], term) {
+			end++
+		}
+		slots = append(slots, idx.terms[start:end])
+	}
+
+	return slots
+}
+
+// matching returns ids of quotes where the slots follow each other.
+func (idx *Index) matching(slots [][]string) map[types.Id]struct{} {
+	result := make(map[types.Id]struct{})
+	for _, term := range slots[0] {
+		for id, positions := range idx.postings[term] {
+			if _, ok := result[id]; ok {
+				continue
+			}
+			for _, pos := range positions {
+				if idx.followed(id, pos+1, slots[1:]) {
+					result[id] = struct{}{}
+					break
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// followed reports whether slots appear in the quote starting at pos.
+func (idx *Index) followed(id types.Id, pos int, slots [][]string) bool {
+	if len(slots) == 0 {
+		return true
+	}
+
+	for _, term := range slots[0] {
+		_, found := slices.BinarySearch(idx.postings[term][id], pos)
+		if found && idx.followed(id, pos+1, slots[1:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (idx *Index) score(id types.Id, expanded [][][]string) Hit {
+	hit := Hit{Id: id}
+
+	docs := float64(len(idx.docs))
+	avgLength := float64(idx.totalLength) / docs
+	length := float64(idx.docs[id].length)
+
+	// a word of the query is scored by the best of its forms, otherwise a
+	// quote would score twice for a word equal to its stem
+	for _, slots := range expanded {
+		for _, slot := range slots {
+			best := 0.0
+			for _, term := range slot {
+				postings := idx.postings[term]
+				freq := float64(len(postings[id]))
+				if freq == 0 {
+					continue
+				}
+
+				n := float64(len(postings))
+				idf := math.Log(1 + (docs-n+0.5)/(n+0.5))
+				best = max(best, idf*freq*(k1+1)/(freq+k1*(1-b+b*length/avgLength)))
+
+				if !slices.Contains(hit.Terms, term) {
+					hit.Terms = append(hit.Terms, term)
+				}
+			}
+			hit.Score += best
+		}
+	}
+
+	return hit
+}
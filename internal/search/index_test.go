@@ -0,0 +1,316 @@
+package search
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+func hitIds(hits []Hit) []types.Id {
+	ids := make([]types.Id, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.Id)
+	}
+	return ids
+}
+
+func TestParseQuery(t *testing.T) {
+	type output struct {
+		Query Query
+		Err   error
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected output
+	}{
+		{
+			name:     "Empty",
+			input:    "",
+			expected: output{Err: ErrEmptyQuery},
+		},
+		{
+			name:     "OnlyPunctuation",
+			input:    `"" * -`,
+			expected: output{Err: ErrEmptyQuery},
+		},
+		{
+			name:  "Words",
+			input: "  Love  WAR ",
+			expected: output{Query: Query{clauses: []clause{
+				{terms: []string{"love"}, stems: []string{"love"}},
+				{terms: []string{"war"}, stems: []string{"war"}},
+			}}},
+		},
+		{
+			name:  "Phrase",
+			input: `"to be, or not" question`,
+			expected: output{Query: Query{clauses: []clause{
+				{terms: []string{"to", "be", "or", "not"}, stems: []string{"to", "be", "or", "not"}},
+				{terms: []string{"question"}, stems: []string{"question"}},
+			}}},
+		},
+		{
+			name:  "UnicodeSpaces",
+			input: "love\u00a0\"war peace\"\u3000life",
+			expected: output{Query: Query{clauses: []clause{
+				{terms: []string{"love"}, stems: []string{"love"}},
+				{terms: []string{"war", "peace"}, stems: []string{"war", "peac"}},
+				{terms: []string{"life"}, stems: []string{"life"}},
+			}}},
+		},
+		{
+			name:  "UnterminatedPhrase",
+			input: `life "is what`,
+			expected: output{Query: Query{clauses: []clause{
+				{terms: []string{"life"}, stems: []string{"life"}},
+				{terms: []string{"is", "what"}, stems: []string{"is", "what"}},
+			}}},
+		},
+		{
+			name:  "Prefix",
+			input: `любов* "not to b*"`,
+			expected: output{Query: Query{clauses: []clause{
+				{terms: []string{"любов"}, stems: []string{"любов"}, prefix: true},
+				{terms: []string{"not", "to", "b"}, stems: []string{"not", "to", "b"}, prefix: true},
+			}}},
+		},
+		{
+			name:  "Stemmed",
+			input: "Loving hearts",
+			expected: output{Query: Query{clauses: []clause{
+				{terms: []string{"loving"}, stems: []string{"love"}},
+				{terms: []string{"hearts"}, stems: []string{"heart"}},
+			}}},
+		},
+		{
+			name:  "StemmedRussian",
+			input: "Любви",
+			expected: output{Query: Query{clauses: []clause{
+				{terms: []string{"любви"}, stems: []string{"любв"}},
+			}}},
+		},
+		{
+			name:  "PunctuatedWord",
+			input: "don't",
+			expected: output{Query: Query{clauses: []clause{
+				{terms: []string{"don", "t"}, stems: []string{"don", "t"}},
+			}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := ParseQuery(tc.input)
+			if err != tc.expected.Err {
+				t.Errorf("parser returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(query, tc.expected.Query) {
+				t.Errorf("parser returned unexpected query: got %v want %v", query, tc.expected.Query)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(1, "To be, or not to be, that is the question.")
+	idx.Add(2, "Love all, trust a few, do wrong to none.")
+	idx.Add(3, "Love, love, love is all you need.")
+	idx.Add(4, "Не то, что мните вы, природа: не слепок, не бездушный лик.")
+	idx.Add(5, "The question is not to be answered.")
+	idx.Add(6, "Любовь долго терпит, милосердствует.")
+
+	testCases := []struct {
+		name     string
+		input    string
+		limit    int
+		expected []types.Id
+	}{
+		{
+			name:     "SingleWord",
+			input:    "question",
+			expected: []types.Id{5, 1},
+		},
+		{
+			name:     "RankedByFrequency",
+			input:    "love",
+			expected: []types.Id{3, 2},
+		},
+		{
+			name:     "AllWordsRequired",
+			input:    "love trust",
+			expected: []types.Id{2},
+		},
+		{
+			name:     "Phrase",
+			input:    `"not to be"`,
+			expected: []types.Id{1, 5},
+		},
+		{
+			name:     "PhraseOrder",
+			input:    `"to be or not"`,
+			expected: []types.Id{1},
+		},
+		{
+			name:     "PhraseAcrossWords",
+			input:    `"be that"`,
+			expected: []types.Id{1},
+		},
+		{
+			name:     "Prefix",
+			input:    "quest*",
+			expected: []types.Id{5, 1},
+		},
+		{
+			name:     "PhrasePrefix",
+			input:    `"is the q*"`,
+			expected: []types.Id{1},
+		},
+		{
+			name:     "StemmedWord",
+			input:    "loved",
+			expected: []types.Id{3, 2},
+		},
+		{
+			name:     "StemmedRussianWord",
+			input:    "любви",
+			expected: []types.Id{6},
+		},
+		{
+			name:     "StemmedPhrase",
+			input:    `"questions are"`,
+			expected: []types.Id{},
+		},
+		{
+			name:     "StemmedPhraseMatch",
+			input:    `"not to be answering"`,
+			expected: []types.Id{5},
+		},
+		{
+			name:     "PrefixOfWord",
+			input:    "милосердс*",
+			expected: []types.Id{6},
+		},
+		{
+			name:     "Cyrillic",
+			input:    "ПРИРОДА",
+			expected: []types.Id{4},
+		},
+		{
+			name:     "Limit",
+			input:    "to",
+			limit:    2,
+			expected: []types.Id{1, 5},
+		},
+		{
+			name:     "NoMatches",
+			input:    "hate",
+			expected: []types.Id{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := ParseQuery(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hits := idx.Search(query, tc.limit)
+			if got := hitIds(hits); !slices.Equal(got, tc.expected) {
+				t.Errorf("index returned unexpected hits: got %v want %v", got, tc.expected)
+			}
+		})
+	}
+
+	t.Run("MatchedTerms", func(t *testing.T) {
+		query, _ := ParseQuery("quest* answered")
+		hits := idx.Search(query, 0)
+		if len(hits) != 1 || !slices.Equal(hits[0].Terms, []string{"question", "answered", "answer"}) {
+			t.Errorf("index returned unexpected hits: %v", hits)
+		}
+	})
+
+	t.Run("RemovedQuote", func(t *testing.T) {
+		idx.Remove(3)
+		idx.Remove(3)
+
+		query, _ := ParseQuery("need")
+		if hits := idx.Search(query, 0); len(hits) != 0 {
+			t.Errorf("index returned unexpected hits: %v", hits)
+		}
+		if slices.Contains(idx.terms, "need") {
+			t.Errorf("index kept term of removed quote")
+		}
+
+		query, _ = ParseQuery("love")
+		if got := hitIds(idx.Search(query, 0)); !slices.Equal(got, []types.Id{2}) {
+			t.Errorf("index returned unexpected hits: got %v want %v", got, []types.Id{2})
+		}
+	})
+
+	t.Run("EqualScores", func(t *testing.T) {
+		idx := NewIndex()
+		idx.Add(math.MaxUint64, "Same text")
+		idx.Add(1, "Same text")
+
+		query, _ := ParseQuery("same")
+		expected := []types.Id{1, math.MaxUint64}
+		if got := hitIds(idx.Search(query, 0)); !slices.Equal(got, expected) {
+			t.Errorf("index returned unexpected hits: got %v want %v", got, expected)
+		}
+	})
+}
+
+func TestSnippet(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		terms    []string
+		expected string
+	}{
+		{
+			name:     "ShortText",
+			text:     "Love all, trust a few.",
+			terms:    []string{"love", "few"},
+			expected: "<mark>Love</mark> all, trust a <mark>few</mark>.",
+		},
+		{
+			name:     "Stemmed",
+			text:     "Loved and loving, we love",
+			terms:    []string{"love"},
+			expected: "<mark>Loved</mark> and <mark>loving</mark>, we <mark>love</mark>",
+		},
+		{
+			name:     "Escaped",
+			text:     "<b>Bold</b> & brave",
+			terms:    []string{"brave"},
+			expected: "&lt;b&gt;Bold&lt;/b&gt; &amp; <mark>brave</mark>",
+		},
+		{
+			name:     "LongText",
+			text:     "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twenty-one twenty-two twenty-three twenty-four twenty-five",
+			terms:    []string{"ten"},
+			expected: "…four five six seven eight nine <mark>ten</mark> eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twenty-one twenty-two twenty-three twenty…",
+		},
+		{
+			name:     "LongTextStart",
+			text:     "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twenty-one twenty-two twenty-three twenty-four twenty-five",
+			terms:    []string{"two"},
+			expected: "one <mark>two</mark> three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twenty-one twenty-<mark>two</mark>…",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			snippet := Snippet(tc.text, tc.terms)
+			if snippet != tc.expected {
+				t.Errorf("snippet is unexpected: got %v want %v", snippet, tc.expected)
+			}
+		})
+	}
+}
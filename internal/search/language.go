@@ -0,0 +1,77 @@
+package search
+
+import "unicode"
+
+type language int
+
+const (
+	languageUnknown language = iota
+	languageRussian
+	languageEnglish
+)
+
+// detectLanguage guesses the language of a text by its words. Most words of
+// e.g. ukrainian are spelled with letters shared with russian, so words with
+// other letters, like "ї" or "ß", are a strong sign of another language: a
+// text is not stemmed if at least a quarter of its words have such letters.
+func detectLanguage(tokens []token) language {
+	russian, english, other := 0, 0, 0
+	for _, t := range tokens {
+		switch {
+		case isRussianWord(t.term):
+			russian++
+		case isASCIIWord(t.term):
+			if hasLetters(t.term) {
+				english++
+			}
+		default:
+			other++
+		}
+	}
+
+	switch {
+	case other*4 >= russian+english+other:
+		return languageUnknown
+	case russian > english:
+		return languageRussian
+	case english > russian:
+		return languageEnglish
+	default:
+		return languageUnknown
+	}
+}
+
+func hasLetters(word string) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stem returns the stem of word in language. Words written in another script
+// are left as is.
+func stem(word string, lang language) string {
+	switch lang {
+	case languageRussian:
+		return stemRussian(word)
+	case languageEnglish:
+		return stemEnglish(word)
+	default:
+		return word
+	}
+}
+
+// analyze splits text into words and finds their stems using the language of
+// the whole text.
+func analyze(text string) []token {
+	tokens := tokenize(text)
+	lang := detectLanguage(tokens)
+	for i := range tokens {
+		tokens[i].stem = stem(tokens[i].term, lang)
+	}
+
+	return tokens
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrEmptyQuery = errors.New("search query should contain at least one word")
+
+// clause is a single word or a phrase of consecutive words. Each word matches
+// the same word or a word with the same stem. If prefix is set the last word
+// matches any word starting with it instead.
+type clause struct {
+	terms  []string
+	stems  []string
+	prefix bool
+}
+
+// Query is a parsed search query. A quote matches the query if it matches
+// every clause.
+type Query struct {
+	clauses []clause
+}
+
+// ParseQuery parses words and phrases in double quotes. A word ending with
+// "*" matches any word starting with it, e.g. "welt*" matches "weltschmerz".
+// A word containing punctuation, e.g. "don't", is searched as a phrase.
+func ParseQuery(q string) (Query, error) {
+	query := Query{}
+	words := make([]token, 0)
+	for len(q) > 0 {
+		var chunk string
+		if q[0] == '"' {
+			// an unterminated phrase lasts until the end of the query
+			end := strings.IndexByte(q[1:], '"')
+			if end < 0 {
+				chunk, q = q[1:], ""
+			} else {
+				chunk, q = q[1:end+1], q[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(q, func(r rune) bool {
+				return unicode.IsSpace(r) || r == '"'
+			})
+			if end < 0 {
+				end = len(q)
+			}
+			chunk, q = q[:end], strings.TrimLeftFunc(q[end:], unicode.IsSpace)
+		}
+
+		tokens := tokenize(chunk)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		last := tokens[len(tokens)-1]
+		query.clauses = append(query.clauses, clause{
+			terms:  terms(tokens),
+			prefix: strings.HasPrefix(chunk[last.end:], "*"),
+		})
+		words = append(words, tokens...)
+	}
+
+	if len(query.clauses) == 0 {
+		return Query{}, ErrEmptyQuery
+	}
+
+	// the language is detected for the whole query as it is for quotes
+	lang := detectLanguage(words)
+	for i, c := range query.clauses {
+		query.clauses[i].stems = make([]string, 0, len(c.terms))
+		for _, term := range c.terms {
+			query.clauses[i].stems = append(query.clauses[i].stems, stem(term, lang))
+		}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,172 @@
+package search
+
+import (
+	"slices"
+	"strings"
+)
+
+// Russian stemming follows the Snowball Russian stemmer, see
+// https://snowballstem.org/algorithms/russian/stemmer.html
+
+// russianEndings is a group of endings. Endings of a group marked with
+// afterA must follow "а" or "я", which stays in the stem.
+type russianEndings struct {
+	endings []string
+	afterA  bool
+}
+
+var (
+	russianPerfectiveGerund = []russianEndings{
+		{endings: []string{"в", "вши", "вшись"}, afterA: true},
+		{endings: []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}},
+	}
+	russianAdjective = []russianEndings{{endings: []string{
+		"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}}}
+	russianParticiple = []russianEndings{
+		{endings: []string{"ем", "нн", "вш", "ющ", "щ"}, afterA: true},
+		{endings: []string{"ивш", "ывш", "ующ"}},
+	}
+	russianReflexive = []russianEndings{{endings: []string{"ся", "сь"}}}
+	russianVerb      = []russianEndings{
+		{endings: []string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"}, afterA: true},
+		{endings: []string{
+			"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+			"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю",
+		}},
+	}
+	russianNoun = []russianEndings{{endings: []string{
+		"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+		"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я",
+	}}}
+	russianSuperlative  = []russianEndings{{endings: []string{"ейш", "ейше"}}}
+	russianDerivational = []russianEndings{{endings: []string{"ост", "ость"}}}
+)
+
+func isRussianVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+// isRussianWord reports whether word consists of lowercase russian letters
+// only.
+func isRussianWord(word string) bool {
+	for _, r := range word {
+		if (r < 'а' || r > 'я') && r != 'ё' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// removeEnding removes the longest of endings which lies in word[start:].
+// Nothing is removed if the longest ending does not satisfy its group.
+func removeEnding(word []rune, start int, groups []russianEndings) ([]rune, bool) {
+	longest, afterA := "", false
+	for _, group := range groups {
+		for _, ending := range group.endings {
+			n := len([]rune(ending))
+			if n <= len([]rune(longest)) || len(word)-n < start {
+				continue
+			}
+			if strings.HasSuffix(string(word), ending) {
+				longest, afterA = ending, group.afterA
+			}
+		}
+	}
+
+	n := len([]rune(longest))
+	if n == 0 {
+		return word, false
+	}
+
+	if afterA {
+		i := len(word) - n - 1
+		if i < start || (word[i] != 'а' && word[i] != 'я') {
+			return word, false
+		}
+	}
+
+	return word[:len(word)-n], true
+}
+
+// russianRegion returns the position after the first non-vowel following a
+// vowel starting from start.
+func russianRegion(word []rune, start int) int {
+	for i := start + 1; i < len(word); i++ {
+		if !isRussianVowel(word[i]) && isRussianVowel(word[i-1]) {
+			return i + 1
+		}
+	}
+
+	return len(word)
+}
+
+func stemRussian(word string) string {
+	if !isRussianWord(word) {
+		return word
+	}
+
+	return string(dropFleetingVowel(snowballRussian([]rune(strings.ReplaceAll(word, "ё", "е")))))
+}
+
+func snowballRussian(w []rune) []rune {
+	rv := slices.IndexFunc(w, isRussianVowel) + 1
+	if rv == 0 {
+		return w
+	}
+	r2 := russianRegion(w, russianRegion(w, 0))
+
+	// step 1
+	if stem, ok := removeEnding(w, rv, russianPerfectiveGerund); ok {
+		w = stem
+	} else {
+		w, _ = removeEnding(w, rv, russianReflexive)
+		if stem, ok := removeEnding(w, rv, russianAdjective); ok {
+			w, _ = removeEnding(stem, rv, russianParticiple)
+		} else if stem, ok := removeEnding(w, rv, russianVerb); ok {
+			w = stem
+		} else {
+			w, _ = removeEnding(w, rv, russianNoun)
+		}
+	}
+
+	// step 2
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// step 3
+	w, _ = removeEnding(w, r2, russianDerivational)
+
+	// step 4
+	if stem, ok := removeEnding(w, rv, russianSuperlative); ok {
+		w = stem
+	}
+	switch {
+	case len(w)-2 >= rv && strings.HasSuffix(string(w), "нн"):
+		w = w[:len(w)-1]
+	case len(w) > rv && w[len(w)-1] == 'ь':
+		w = w[:len(w)-1]
+	}
+
+	return w
+}
+
+// dropFleetingVowel removes "о" or "е" between the last two consonants of a
+// stem. Snowball keeps such vowels, so "любовь" and "любви" or "отец" and
+// "отца" would get different stems otherwise. The vowel is removed from every
+// stem, even if it is not fleeting, which only makes a few unrelated words
+// share a stem.
+func dropFleetingVowel(w []rune) []rune {
+	n := len(w)
+	if n < 4 || isRussianVowel(w[n-1]) || isRussianVowel(w[n-3]) || w[n-1] == 'ь' || w[n-1] == 'й' {
+		return w
+	}
+	if w[n-2] != 'о' && w[n-2] != 'е' {
+		return w
+	}
+
+	return append(w[:n-2], w[n-1])
+}
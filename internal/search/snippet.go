@@ -0,0 +1,73 @@
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+)
+
+const (
+	// snippetWords is the maximum number of words in a snippet
+	snippetWords = 24
+	// snippetContext is the number of words shown before the first match
+	snippetContext = 6
+
+	highlightStart = "<mark>"
+	highlightEnd   = "</mark>"
+	ellipsis       = "…"
+)
+
+// Snippet returns a fragment of text around the first word matching terms
+// with all matching words wrapped into <mark> tags. A word matches if it or
+// its stem is one of terms. The rest of the text is
+// HTML-escaped, so the snippet can be inserted into a page as is.
+func Snippet(text string, terms []string) string {
+	tokens := analyze(text)
+	if len(tokens) == 0 {
+		return html.EscapeString(text)
+	}
+
+	matches := func(t token) bool {
+		return slices.Contains(terms, t.term) || slices.Contains(terms, t.stem)
+	}
+	first := slices.IndexFunc(tokens, matches)
+
+	from, to := 0, len(tokens)
+	if len(tokens) > snippetWords {
+		from = max(first-snippetContext, 0)
+		to = min(from+snippetWords, len(tokens))
+		from = max(to-snippetWords, 0)
+	}
+
+	start, end := tokens[from].start, tokens[to-1].end
+	if from == 0 {
+		start = 0
+	}
+	if to == len(tokens) {
+		end = len(text)
+	}
+
+	builder := strings.Builder{}
+	if from > 0 {
+		builder.WriteString(ellipsis)
+	}
+
+	offset := start
+	for _, t := range tokens[from:to] {
+		if !matches(t) {
+			continue
+		}
+		builder.WriteString(html.EscapeString(text[offset:t.start]))
+		builder.WriteString(highlightStart)
+		builder.WriteString(html.EscapeString(text[t.start:t.end]))
+		builder.WriteString(highlightEnd)
+		offset = t.end
+	}
+	builder.WriteString(html.EscapeString(text[offset:end]))
+
+	if to < len(tokens) {
+		builder.WriteString(ellipsis)
+	}
+
+	return builder.String()
+}
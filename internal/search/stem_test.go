@@ -0,0 +1,142 @@
+package search
+
+import "testing"
+
+func TestStemEnglish(t *testing.T) {
+	testCases := map[string]string{
+		"a":             "a",
+		"cats":          "cat",
+		"caresses":      "caress",
+		"ponies":        "poni",
+		"ties":          "tie",
+		"cried":         "cri",
+		"cry":           "cri",
+		"agreed":        "agre",
+		"running":       "run",
+		"hopping":       "hop",
+		"hoping":        "hope",
+		"loving":        "love",
+		"loved":         "love",
+		"love":          "love",
+		"happiness":     "happi",
+		"generously":    "generous",
+		"national":      "nation",
+		"relational":    "relat",
+		"consign":       "consign",
+		"consignment":   "consign",
+		"consistency":   "consist",
+		"consistently":  "consist",
+		"consolation":   "consol",
+		"consolatory":   "consolatori",
+		"consolidating": "consolid",
+		"consolingly":   "consol",
+		"conspicuously": "conspicu",
+		"conspiracy":    "conspiraci",
+		"conspirators":  "conspir",
+		"constables":    "constabl",
+		"constancy":     "constanc",
+		"dying":         "die",
+		"skies":         "sky",
+		"news":          "news",
+		"succeeding":    "succeed",
+		"1869":          "1869",
+		"café":          "café",
+	}
+
+	for word, expected := range testCases {
+		t.Run(word, func(t *testing.T) {
+			if stem := stemEnglish(word); stem != expected {
+				t.Errorf("stemmer returned unexpected stem: got %v want %v", stem, expected)
+			}
+		})
+	}
+}
+
+func TestSnowballRussian(t *testing.T) {
+	testCases := map[string]string{
+		"в":            "в",
+		"вагона":       "вагон",
+		"вагонов":      "вагон",
+		"важная":       "важн",
+		"важнее":       "важн",
+		"важнейшими":   "важн",
+		"важничал":     "важнича",
+		"важного":      "важн",
+		"важности":     "важност",
+		"важностью":    "важност",
+		"вазах":        "ваз",
+		"валентина":    "валентин",
+		"валериановых": "валерианов",
+		"валетами":     "валет",
+		"валился":      "вал",
+		"валится":      "вал",
+		"валялась":     "валя",
+		"валяются":     "валя",
+		"любовь":       "любов",
+		"любви":        "любв",
+	}
+
+	for word, expected := range testCases {
+		t.Run(word, func(t *testing.T) {
+			if stem := string(snowballRussian([]rune(word))); stem != expected {
+				t.Errorf("stemmer returned unexpected stem: got %v want %v", stem, expected)
+			}
+		})
+	}
+}
+
+func TestStemRussian(t *testing.T) {
+	testCases := []struct {
+		words []string
+		same  bool
+	}{
+		{words: []string{"любовь", "любви", "любовью"}, same: true},
+		{words: []string{"отец", "отца", "отцом"}, same: true},
+		{words: []string{"ветер", "ветра"}, same: true},
+		{words: []string{"ёлка", "елки"}, same: true},
+		{words: []string{"читать", "читаешь", "прочитавши"}, same: false},
+		{words: []string{"мир", "мира", "миром"}, same: true},
+		{words: []string{"мир", "мера"}, same: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.words[0], func(t *testing.T) {
+			stems := make(map[string]bool)
+			for _, word := range tc.words {
+				stems[stemRussian(word)] = true
+			}
+			if (len(stems) == 1) != tc.same {
+				t.Errorf("stemmer returned unexpected stems: %v", stems)
+			}
+		})
+	}
+
+	t.Run("OtherScript", func(t *testing.T) {
+		if stem := stemRussian("loving"); stem != "loving" {
+			t.Errorf("stemmer returned unexpected stem: got %v want %v", stem, "loving")
+		}
+	})
+}
+
+func TestDetectLanguage(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected language
+	}{
+		{text: "Не то, что мните вы, природа", expected: languageRussian},
+		{text: "All you need is love", expected: languageEnglish},
+		{text: "Цитата из Hamlet в переводе", expected: languageRussian},
+		{text: "Ще не вмерла України і слава, і воля", expected: languageUnknown},
+		{text: "Größe", expected: languageUnknown},
+		{text: "Зачем нужен café, если есть чайная", expected: languageRussian},
+		{text: "1984", expected: languageUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			if lang := detectLanguage(tokenize(tc.text)); lang != tc.expected {
+				t.Errorf("detector returned unexpected language: got %v want %v", lang, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token is a word of a text together with its byte offsets in the text.
+// stem is set by analyze.
+type token struct {
+	term       string
+	stem       string
+	start, end int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// tokenize splits text into lowercase words with "ё" replaced by "е".
+// Everything except letters and digits separates words.
+func tokenize(text string) []token {
+	tokens := make([]token, 0)
+
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{term: normalize(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: normalize(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+func normalize(word string) string {
+	return strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+}
+
+// terms returns terms of tokens in the same order.
+func terms(tokens []token) []string {
+	result := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, t.term)
+	}
+
+	return result
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/stores"
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+type AuthorsService interface {
+	Get() ([]types.AuthorData, error)
+	// Suggest returns authors for autocompletion of a name being typed.
+	Suggest(request types.SuggestAuthorsRequest) ([]types.AuthorData, error)
+	GetById(id types.Id) (types.AuthorData, error)
+	// GetQuotes lists quotes of the author with specified id like
+	// QuotesService.Get does.
+	GetQuotes(id types.Id, request types.GetQuotesRequest) (types.QuotesPage, error)
+	Patch(id types.Id, request types.PatchAuthorRequest) (types.AuthorData, error)
+	// Merge merges the author with specified id into another one and returns
+	// the resulting author.
+	Merge(id types.Id, request types.MergeAuthorsRequest) (types.AuthorData, error)
+}
+
+type authorsService struct {
+	authorsStore  stores.AuthorsStore
+	quotesService QuotesService
+}
+
+func NewAuthorsService(authorsStore stores.AuthorsStore, quotesService QuotesService) AuthorsService {
+	return &authorsService{authorsStore: authorsStore, quotesService: quotesService}
+}
+
+func (as *authorsService) Get() ([]types.AuthorData, error) {
+	return as.authorsStore.GetAuthors()
+}
+
+func (as *authorsService) Suggest(request types.SuggestAuthorsRequest) ([]types.AuthorData, error) {
+	err := request.Validate()
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	match := request.Match
+	if match == "" {
+		match = types.AuthorMatchTranslit
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = types.DefaultSuggestLimit
+	}
+
+	return as.authorsStore.SuggestAuthors(request.Prefix, match, limit)
+}
+
+func (as *authorsService) GetById(id types.Id) (types.AuthorData, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.AuthorData{}, invalidInput(err)
+	}
+
+	return as.authorsStore.GetAuthorById(id)
+}
+
+func (as *authorsService) GetQuotes(id types.Id, request types.GetQuotesRequest) (types.QuotesPage, error) {
+	_, err := as.GetById(id)
+	if err != nil {
+		return types.QuotesPage{}, err
+	}
+
+	request.AuthorId = id
+	return as.quotesService.Get(request)
+}
+
+func (as *authorsService) Patch(id types.Id, request types.PatchAuthorRequest) (types.AuthorData, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.AuthorData{}, invalidInput(err)
+	}
+
+	return as.authorsStore.UpdateAuthor(id, func(author *types.AuthorData) error {
+		patched := request.Apply(*author)
+		// quotes keep the old name, so it stays an alias unless aliases are
+		// replaced by the request; duplicates are dropped by NormalizeAuthor
+		if request.Aliases == nil {
+			patched.Aliases = append(patched.Aliases, author.Name)
+		}
+		patched = types.NormalizeAuthor(patched)
+
+		err := patched.Validate()
+		if err != nil {
+			return invalidInput(err)
+		}
+
+		*author = patched
+		return nil
+	})
+}
+
+func (as *authorsService) Merge(id types.Id, request types.MergeAuthorsRequest) (types.AuthorData, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.AuthorData{}, invalidInput(err)
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return types.AuthorData{}, invalidInput(err)
+	}
+
+	if request.Into == id {
+		return types.AuthorData{}, invalidInput(fmt.Errorf("author cannot be merged into itself"))
+	}
+
+	return as.authorsStore.MergeAuthors(id, request.Into, request.Actor)
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+type authorsStoreStub struct{}
+
+func (as *authorsStoreStub) GetAuthors() ([]types.AuthorData, error) {
+	return []types.AuthorData{{Id: 1, Name: "Author"}}, nil
+}
+
+func (as *authorsStoreStub) SuggestAuthors(prefix types.Author, match types.AuthorMatch, limit int) ([]types.AuthorData, error) {
+	authors := []types.AuthorData{{Id: 1, Name: "Author"}, {Id: 2, Name: "Another"}}
+	if match != types.AuthorMatchTranslit {
+		authors = authors[:1]
+	}
+
+	return authors[:min(limit, len(authors))], nil
+}
+
+func (as *authorsStoreStub) GetAuthorById(id types.Id) (types.AuthorData, error) {
+	if id != 1 {
+		return types.AuthorData{}, ErrAuthorNotFound
+	}
+
+	return types.AuthorData{Id: id, Name: "Author", Aliases: []types.Author{"Alias"}}, nil
+}
+
+func (as *authorsStoreStub) UpdateAuthor(id types.Id, modify func(author *types.AuthorData) error) (types.AuthorData, error) {
+	author, err := as.GetAuthorById(id)
+	if err != nil {
+		return types.AuthorData{}, err
+	}
+
+	err = modify(&author)
+	if err != nil {
+		return types.AuthorData{}, err
+	}
+
+	return author, nil
+}
+
+func (as *authorsStoreStub) MergeAuthors(id types.Id, into types.Id, actor string) (types.AuthorData, error) {
+	if id != 2 || into != 1 {
+		return types.AuthorData{}, ErrAuthorNotFound
+	}
+
+	return types.AuthorData{Id: into, Name: "Author", Aliases: []types.Author{"Alias", "Other"}}, nil
+}
+
+func TestGetAuthorQuotes(t *testing.T) {
+	authorsService := NewAuthorsService(&authorsStoreStub{}, NewQuotesService(&quotesStoreStub{}))
+
+	type input struct {
+		Id      types.Id
+		Request types.GetQuotesRequest
+	}
+
+	type output struct {
+		Page types.QuotesPage
+		Err  error
+	}
+
+	testCases := []struct {
+		name     string
+		input    input
+		expected output
+	}{
+		{
+			name:     "ZeroId",
+			input:    input{Id: 0},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownId",
+			input:    input{Id: 2},
+			expected: output{Err: ErrAuthorNotFound},
+		},
+		{
+			name:     "CorrectId",
+			input:    input{Id: 1},
+			expected: output{Page: types.QuotesPage{Quotes: []types.QuoteData{{Id: 1}}}},
+		},
+		{
+			name:     "InvalidRequest",
+			input:    input{Id: 1, Request: types.GetQuotesRequest{Sort: "quote"}},
+			expected: output{Err: ErrInvalidInput},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := authorsService.GetQuotes(tc.input.Id, tc.input.Request)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(page.Quotes, tc.expected.Page.Quotes) {
+				t.Errorf("service returned unexpected quotes: got %v want %v", page.Quotes, tc.expected.Page.Quotes)
+			}
+		})
+	}
+}
+
+func TestPatchAuthor(t *testing.T) {
+	authorsService := NewAuthorsService(&authorsStoreStub{}, NewQuotesService(&quotesStoreStub{}))
+
+	var (
+		newName   types.Author = "  New   Name "
+		blank     types.Author = " "
+		birthYear              = 1828
+		deathYear              = 1810
+		bio                    = " Bio "
+	)
+
+	type input struct {
+		Id      types.Id
+		Request types.PatchAuthorRequest
+	}
+
+	type output struct {
+		Author types.AuthorData
+		Err    error
+	}
+
+	testCases := []struct {
+		name     string
+		input    input
+		expected output
+	}{
+		{
+			name:     "ZeroId",
+			input:    input{Id: 0, Request: types.PatchAuthorRequest{Bio: &bio}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownId",
+			input:    input{Id: 2, Request: types.PatchAuthorRequest{Bio: &bio}},
+			expected: output{Err: ErrAuthorNotFound},
+		},
+		{
+			name:     "BlankName",
+			input:    input{Id: 1, Request: types.PatchAuthorRequest{Name: &blank}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "DeathBeforeBirth",
+			input:    input{Id: 1, Request: types.PatchAuthorRequest{BirthYear: &birthYear, DeathYear: &deathYear}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:  "Bio",
+			input: input{Id: 1, Request: types.PatchAuthorRequest{BirthYear: &birthYear, Bio: &bio}},
+			expected: output{Author: types.AuthorData{
+				Id:        1,
+				Name:      "Author",
+				Aliases:   []types.Author{"Alias"},
+				BirthYear: birthYear,
+				Bio:       "Bio",
+			}},
+		},
+		{
+			name:  "RenameKeepsOldName",
+			input: input{Id: 1, Request: types.PatchAuthorRequest{Name: &newName}},
+			expected: output{Author: types.AuthorData{
+				Id:      1,
+				Name:    "New Name",
+				Aliases: []types.Author{"Alias", "Author"},
+			}},
+		},
+		{
+			name:  "RenameReplacesAliases",
+			input: input{Id: 1, Request: types.PatchAuthorRequest{Name: &newName, Aliases: &[]types.Author{}}},
+			expected: output{Author: types.AuthorData{
+				Id:   1,
+				Name: "New Name",
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			author, err := authorsService.Patch(tc.input.Id, tc.input.Request)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(author, tc.expected.Author) {
+				t.Errorf("service returned unexpected author: got %v want %v", author, tc.expected.Author)
+			}
+		})
+	}
+}
+
+func TestMergeAuthors(t *testing.T) {
+	authorsService := NewAuthorsService(&authorsStoreStub{}, NewQuotesService(&quotesStoreStub{}))
+
+	type input struct {
+		Id      types.Id
+		Request types.MergeAuthorsRequest
+	}
+
+	type output struct {
+		Author types.AuthorData
+		Err    error
+	}
+
+	testCases := []struct {
+		name     string
+		input    input
+		expected output
+	}{
+		{
+			name:     "ZeroId",
+			input:    input{Id: 0, Request: types.MergeAuthorsRequest{Into: 1}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "ZeroTarget",
+			input:    input{Id: 2, Request: types.MergeAuthorsRequest{}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "SameAuthor",
+			input:    input{Id: 1, Request: types.MergeAuthorsRequest{Into: 1}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownId",
+			input:    input{Id: 3, Request: types.MergeAuthorsRequest{Into: 1}},
+			expected: output{Err: ErrAuthorNotFound},
+		},
+		{
+			name:     "CorrectIds",
+			input:    input{Id: 2, Request: types.MergeAuthorsRequest{Into: 1}},
+			expected: output{Author: types.AuthorData{Id: 1, Name: "Author", Aliases: []types.Author{"Alias", "Other"}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			author, err := authorsService.Merge(tc.input.Id, tc.input.Request)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(author, tc.expected.Author) {
+				t.Errorf("service returned unexpected author: got %v want %v", author, tc.expected.Author)
+			}
+		})
+	}
+}
+
+func TestSuggestAuthors(t *testing.T) {
+	authorsService := NewAuthorsService(&authorsStoreStub{}, NewQuotesService(&quotesStoreStub{}))
+
+	type output struct {
+		Authors int
+		Err     error
+	}
+
+	testCases := []struct {
+		name     string
+		input    types.SuggestAuthorsRequest
+		expected output
+	}{
+		{
+			name:     "EmptyPrefix",
+			input:    types.SuggestAuthorsRequest{Prefix: " "},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "LimitTooLarge",
+			input:    types.SuggestAuthorsRequest{Prefix: "a", Limit: types.MaxSuggestLimit + 1},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "DefaultLimit",
+			input:    types.SuggestAuthorsRequest{Prefix: "a"},
+			expected: output{Authors: 2},
+		},
+		{
+			name:     "UnknownMatch",
+			input:    types.SuggestAuthorsRequest{Prefix: "a", Match: "fuzzy"},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "ExactMatch",
+			input:    types.SuggestAuthorsRequest{Prefix: "a", Match: types.AuthorMatchExact},
+			expected: output{Authors: 1},
+		},
+		{
+			name:     "SpecifiedLimit",
+			input:    types.SuggestAuthorsRequest{Prefix: "a", Limit: 1},
+			expected: output{Authors: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authors, err := authorsService.Suggest(tc.input)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if len(authors) != tc.expected.Authors {
+				t.Errorf("service returned unexpected number of authors: got %v want %v", len(authors), tc.expected.Authors)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/stores"
+)
+
+var (
+	ErrInvalidInput         = errors.New("invalid input")
+	ErrRevisionNotFound     = errors.New("no revision with specified number")
+	ErrQuoteNotFound        = stores.ErrQuoteNotFound
+	ErrNoQuotes             = stores.ErrNoQuotes
+	ErrCapacityExceeded     = stores.ErrCapacityExceeded
+	ErrAuthorNotFound       = stores.ErrAuthorNotFound
+	ErrAuthorConflict       = stores.ErrAuthorConflict
+	ErrVersionMismatch      = stores.ErrVersionMismatch
+	ErrIdempotencyKeyReused = stores.ErrIdempotencyKeyReused
+	ErrRequestInProgress    = stores.ErrRequestInProgress
+	ErrDuplicateQuote       = stores.ErrDuplicateQuote
+)
+
+// DuplicateQuoteError matches ErrDuplicateQuote and holds the id of the quote
+// already saved.
+type DuplicateQuoteError = stores.DuplicateQuoteError
+
+// invalidInputError keeps the message of a validation error while making it
+// match ErrInvalidInput.
+type invalidInputError struct {
+	err error
+}
+
+func invalidInput(err error) error {
+	return invalidInputError{err: err}
+}
+
+func (e invalidInputError) Error() string {
+	return e.err.Error()
+}
+
+func (e invalidInputError) Unwrap() []error {
+	return []error{ErrInvalidInput, e.err}
+}
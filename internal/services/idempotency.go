@@ -0,0 +1,39 @@
+package services
+
+import (
+	"github.com/NikitaBogoslovskiy/quotes/internal/stores"
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+type IdempotencyService interface {
+	// Begin returns the saved response for a retry of a request or nil if
+	// the request is new, see stores.IdempotencyStore.
+	Begin(key types.IdempotencyKey, fingerprint string) (*types.StoredResponse, error)
+	Complete(key types.IdempotencyKey, response types.StoredResponse)
+	Release(key types.IdempotencyKey)
+}
+
+type idempotencyService struct {
+	idempotencyStore stores.IdempotencyStore
+}
+
+func NewIdempotencyService(idempotencyStore stores.IdempotencyStore) IdempotencyService {
+	return &idempotencyService{idempotencyStore: idempotencyStore}
+}
+
+func (is *idempotencyService) Begin(key types.IdempotencyKey, fingerprint string) (*types.StoredResponse, error) {
+	err := key.Validate()
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	return is.idempotencyStore.Begin(key, fingerprint)
+}
+
+func (is *idempotencyService) Complete(key types.IdempotencyKey, response types.StoredResponse) {
+	is.idempotencyStore.Complete(key, response)
+}
+
+func (is *idempotencyService) Release(key types.IdempotencyKey) {
+	is.idempotencyStore.Release(key)
+}
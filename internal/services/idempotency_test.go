@@ -0,0 +1,46 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/stores"
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+func TestBeginIdempotent(t *testing.T) {
+	idempotencyService := NewIdempotencyService(stores.NewIdempotencyStore(time.Hour))
+
+	testCases := []struct {
+		name     string
+		input    types.IdempotencyKey
+		expected error
+	}{
+		{
+			name:     "EmptyKey",
+			input:    "",
+			expected: ErrInvalidInput,
+		},
+		{
+			name:     "LongKey",
+			input:    types.IdempotencyKey(strings.Repeat("k", 256)),
+			expected: ErrInvalidInput,
+		},
+		{
+			name:     "CorrectKey",
+			input:    "f0e9a3c2-4b1d-4e8a-9c7f-2d6b5a1e3f40",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := idempotencyService.Begin(tc.input, "request")
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected)
+			}
+		})
+	}
+}
@@ -1,15 +1,39 @@
 package services
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/search"
 	"github.com/NikitaBogoslovskiy/quotes/internal/stores"
 	"github.com/NikitaBogoslovskiy/quotes/internal/types"
 )
 
 type QuotesService interface {
-	Create(request types.CreateQuoteRequest) types.CreateQuoteResponse
-	Get(author types.Author) types.GetQuotesResponse
-	GetRandom() types.GetRandomQuoteResponse
-	Delete(id types.Id) types.DeleteQuoteResponse
+	// Create returns DuplicateQuoteError if the author already has the quote,
+	// so do Update, Patch, Revert and Restore.
+	Create(request types.CreateQuoteRequest) (types.Id, error)
+	Get(request types.GetQuotesRequest) (types.QuotesPage, error)
+	Search(request types.SearchQuotesRequest) ([]types.SearchHit, error)
+	GetTags() ([]types.TagCount, error)
+	GetRandom() (types.QuoteData, error)
+	GetById(id types.Id) (types.QuoteData, error)
+	Update(id types.Id, request types.UpdateQuoteRequest) (types.QuoteData, error)
+	Patch(id types.Id, request types.PatchQuoteRequest) (types.QuoteData, error)
+	// Delete deletes the quote if its version matches request.Versions.
+	Delete(id types.Id, request types.DeleteQuoteRequest) error
+	GetTrash() ([]types.QuoteData, error)
+	Restore(id types.Id, request types.RestoreQuoteRequest) (types.QuoteData, error)
+	Purge(id types.Id) error
+	// PurgeTrash removes quotes deleted before deletedBefore or the whole
+	// trash if it is zero.
+	PurgeTrash(deletedBefore time.Time) (int, error)
+	GetRevisions(id types.Id) ([]types.Revision, error)
+	DiffRevisions(id types.Id, request types.DiffRevisionsRequest) (types.RevisionDiff, error)
+	// Revert saves the content of an earlier revision as a new revision.
+	Revert(id types.Id, request types.RevertQuoteRequest) (types.QuoteData, error)
+	// GetDuplicates returns quotes of any author with nearly the same text.
+	GetDuplicates(id types.Id, request types.GetDuplicatesRequest) ([]types.Duplicate, error)
 }
 
 type quotesService struct {
@@ -20,58 +44,319 @@ func NewQuotesService(quotesStore stores.QuotesStore) QuotesService {
 	return &quotesService{quotesStore: quotesStore}
 }
 
-func (qs *quotesService) Create(request types.CreateQuoteRequest) types.CreateQuoteResponse {
+func (qs *quotesService) Create(request types.CreateQuoteRequest) (types.Id, error) {
+	request.Tags = types.NormalizeTags(request.Tags)
+	request.Source = types.NormalizeSource(request.Source)
+
 	err := request.Validate()
 	if err != nil {
-		return types.CreateQuoteResponse{Ok: false, Message: err.Error()}
+		return 0, invalidInput(err)
 	}
 
-	id, err := qs.quotesStore.Create(request.Author, request.Quote)
+	return qs.quotesStore.Create(types.QuoteData{
+		Author:      request.Author,
+		Quote:       request.Quote,
+		Tags:        request.Tags,
+		Source:      request.Source,
+		Attribution: request.Attribution,
+		UpdatedBy:   request.Actor,
+	})
+}
+
+func (qs *quotesService) Get(request types.GetQuotesRequest) (types.QuotesPage, error) {
+	request.Tags = types.NormalizeTags(request.Tags)
+
+	err := request.Validate()
 	if err != nil {
-		return types.CreateQuoteResponse{Ok: false, Message: err.Error()}
+		return types.QuotesPage{}, invalidInput(err)
 	}
 
-	return types.CreateQuoteResponse{Ok: true, Id: id}
-}
+	sort, err := types.ParseSort(request.Sort)
+	if err != nil {
+		return types.QuotesPage{}, invalidInput(err)
+	}
+
+	authorMatch := request.AuthorMatch
+	if authorMatch == "" {
+		authorMatch = types.AuthorMatchTranslit
+	}
 
-func (qs *quotesService) Get(author types.Author) types.GetQuotesResponse {
-	var quotes []types.QuoteData
+	// quotes are filtered by author only if it is specified
+	query := types.QuotesQuery{
+		Author:        request.Author,
+		AuthorMatch:   authorMatch,
+		AuthorId:      request.AuthorId,
+		Tags:          request.Tags,
+		AnyTag:        request.TagMode == "any",
+		Attribution:   request.Attribution,
+		Verified:      request.Verified,
+		CreatedAfter:  request.CreatedAfter,
+		CreatedBefore: request.CreatedBefore,
+		Sort:          sort,
+		Limit:         request.Limit,
+	}
 
-	err := author.Validate()
-	if err == nil { // if author param is specified and valid we filter results by author
-		quotes, err = qs.quotesStore.GetByAuthor(author)
+	if request.Cursor != "" {
+		cursor, err := types.ParseCursor(request.Cursor)
 		if err != nil {
-			return types.GetQuotesResponse{Ok: false, Message: err.Error()}
+			return types.QuotesPage{}, invalidInput(err)
 		}
-	} else { // otherwise return all results
-		quotes, err = qs.quotesStore.GetAll()
-		if err != nil {
-			return types.GetQuotesResponse{Ok: false, Message: err.Error()}
+		if cursor.Sort != sort.String() {
+			return types.QuotesPage{}, invalidInput(fmt.Errorf("cursor was issued for another sort order"))
 		}
+		query.After = &cursor
+	}
+
+	quotes, more, err := qs.quotesStore.List(query)
+	if err != nil {
+		return types.QuotesPage{}, err
+	}
+
+	page := types.QuotesPage{Quotes: quotes}
+	if more && len(quotes) > 0 {
+		page.NextCursor = types.NewCursor(sort, quotes[len(quotes)-1]).String()
+	}
+
+	return page, nil
+}
+
+func (qs *quotesService) Search(request types.SearchQuotesRequest) ([]types.SearchHit, error) {
+	err := request.Validate()
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	query, err := search.ParseQuery(request.Query)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = types.DefaultSearchLimit
+	}
+
+	return qs.quotesStore.Search(query, limit)
+}
+
+func (qs *quotesService) GetTags() ([]types.TagCount, error) {
+	return qs.quotesStore.GetTags()
+}
+
+func (qs *quotesService) GetRandom() (types.QuoteData, error) {
+	return qs.quotesStore.GetRandom()
+}
+
+func (qs *quotesService) GetById(id types.Id) (types.QuoteData, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.QuoteData{}, invalidInput(err)
+	}
+
+	return qs.quotesStore.GetById(id)
+}
+
+func (qs *quotesService) Update(id types.Id, request types.UpdateQuoteRequest) (types.QuoteData, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.QuoteData{}, invalidInput(err)
+	}
+
+	request.Tags = types.NormalizeTags(request.Tags)
+	request.Source = types.NormalizeSource(request.Source)
+
+	err = request.Validate()
+	if err != nil {
+		return types.QuoteData{}, invalidInput(err)
 	}
 
-	return types.GetQuotesResponse{Ok: true, Quotes: quotes}
+	return qs.quotesStore.Update(id, func(quote *types.QuoteData) error {
+		if !request.Versions.Match(quote.Version) {
+			return ErrVersionMismatch
+		}
+		quote.Author = request.Author
+		quote.Quote = request.Quote
+		quote.Tags = request.Tags
+		quote.Source = request.Source
+		quote.Attribution = request.Attribution
+		quote.UpdatedBy = request.Actor
+		return nil
+	})
 }
 
-func (qs *quotesService) GetRandom() types.GetRandomQuoteResponse {
-	quote, err := qs.quotesStore.GetRandom()
+func (qs *quotesService) Patch(id types.Id, request types.PatchQuoteRequest) (types.QuoteData, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.QuoteData{}, invalidInput(err)
+	}
+
+	if request.Tags != nil {
+		tags := types.NormalizeTags(*request.Tags)
+		request.Tags = &tags
+	}
+
+	err = request.Validate()
 	if err != nil {
-		return types.GetRandomQuoteResponse{Ok: false, Message: err.Error()}
+		return types.QuoteData{}, invalidInput(err)
 	}
 
-	return types.GetRandomQuoteResponse{Ok: true, Quote: quote}
+	return qs.quotesStore.Update(id, func(quote *types.QuoteData) error {
+		if !request.Versions.Match(quote.Version) {
+			return ErrVersionMismatch
+		}
+		if request.Author != nil {
+			quote.Author = *request.Author
+		}
+		if request.Quote != nil {
+			quote.Quote = *request.Quote
+		}
+		if request.Tags != nil {
+			quote.Tags = *request.Tags
+		}
+		if request.Source != nil {
+			quote.Source = request.Source.Apply(quote.Source)
+		}
+		if request.Attribution != nil {
+			quote.Attribution = *request.Attribution
+		}
+		quote.UpdatedBy = request.Actor
+		return nil
+	})
 }
 
-func (qs *quotesService) Delete(id types.Id) types.DeleteQuoteResponse {
+func (qs *quotesService) Delete(id types.Id, request types.DeleteQuoteRequest) error {
 	err := id.Validate()
 	if err != nil {
-		return types.DeleteQuoteResponse{Ok: false, Message: err.Error()}
+		return invalidInput(err)
 	}
 
-	err = qs.quotesStore.Delete(id)
+	err = request.Validate()
 	if err != nil {
-		return types.DeleteQuoteResponse{Ok: false, Message: err.Error()}
+		return invalidInput(err)
+	}
+
+	return qs.quotesStore.Delete(id, request.Versions, request.Actor)
+}
+
+func (qs *quotesService) GetTrash() ([]types.QuoteData, error) {
+	return qs.quotesStore.GetTrash()
+}
+
+func (qs *quotesService) Restore(id types.Id, request types.RestoreQuoteRequest) (types.QuoteData, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.QuoteData{}, invalidInput(err)
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return types.QuoteData{}, invalidInput(err)
+	}
+
+	return qs.quotesStore.Restore(id, request.Actor)
+}
+
+func (qs *quotesService) Purge(id types.Id) error {
+	err := id.Validate()
+	if err != nil {
+		return invalidInput(err)
+	}
+
+	return qs.quotesStore.Purge(id)
+}
+
+func (qs *quotesService) PurgeTrash(deletedBefore time.Time) (int, error) {
+	return qs.quotesStore.PurgeTrash(deletedBefore)
+}
+
+func (qs *quotesService) GetRevisions(id types.Id) ([]types.Revision, error) {
+	err := id.Validate()
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	return qs.quotesStore.GetRevisions(id)
+}
+
+func (qs *quotesService) DiffRevisions(id types.Id, request types.DiffRevisionsRequest) (types.RevisionDiff, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.RevisionDiff{}, invalidInput(err)
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return types.RevisionDiff{}, invalidInput(err)
+	}
+
+	revisions, err := qs.quotesStore.GetRevisions(id)
+	if err != nil {
+		return types.RevisionDiff{}, err
+	}
+
+	to := request.To
+	if to == 0 {
+		to = len(revisions)
+	}
+	from := request.From
+	if from == 0 {
+		// the first revision is compared with itself
+		from = max(to-1, 1)
+	}
+	if from > len(revisions) || to > len(revisions) {
+		return types.RevisionDiff{}, ErrRevisionNotFound
+	}
+
+	return types.RevisionDiff{
+		QuoteId: id,
+		From:    from,
+		To:      to,
+		Changes: revisions[from-1].Diff(revisions[to-1]),
+	}, nil
+}
+
+func (qs *quotesService) Revert(id types.Id, request types.RevertQuoteRequest) (types.QuoteData, error) {
+	err := id.Validate()
+	if err != nil {
+		return types.QuoteData{}, invalidInput(err)
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return types.QuoteData{}, invalidInput(err)
+	}
+
+	revisions, err := qs.quotesStore.GetRevisions(id)
+	if err != nil {
+		return types.QuoteData{}, err
+	}
+	if request.To > len(revisions) {
+		return types.QuoteData{}, ErrRevisionNotFound
+	}
+
+	// revisions are never changed, so the one read above is still valid
+	revision := revisions[request.To-1]
+	return qs.quotesStore.Update(id, func(quote *types.QuoteData) error {
+		*quote = revision.Apply(*quote)
+		quote.UpdatedBy = request.Actor
+		return nil
+	})
+}
+
+func (qs *quotesService) GetDuplicates(id types.Id, request types.GetDuplicatesRequest) ([]types.Duplicate, error) {
+	err := id.Validate()
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	if request.Threshold == 0 {
+		request.Threshold = types.DefaultDuplicateThreshold
 	}
 
-	return types.DeleteQuoteResponse{Ok: true}
+	return qs.quotesStore.GetDuplicates(id, request.Threshold)
 }
@@ -1,15 +1,25 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
 	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/NikitaBogoslovskiy/quotes/internal/search"
+	"github.com/NikitaBogoslovskiy/quotes/internal/stores"
 	"github.com/NikitaBogoslovskiy/quotes/internal/types"
 )
 
-type quotesStoreStub struct{}
+type quotesStoreStub struct {
+	created types.QuoteData
+}
 
-func (qs *quotesStoreStub) Create(author types.Author, quote types.Quote) (types.Id, error) {
+func (qs *quotesStoreStub) Create(quote types.QuoteData) (types.Id, error) {
+	qs.created = quote
 	return 1, nil
 }
 
@@ -25,91 +35,349 @@ func (qs *quotesStoreStub) GetRandom() (types.QuoteData, error) {
 	return types.QuoteData{}, nil
 }
 
-func (qs *quotesStoreStub) Delete(id types.Id) error {
+func (qs *quotesStoreStub) GetTags() ([]types.TagCount, error) {
+	return []types.TagCount{{Tag: "life", Count: 2}, {Tag: "love", Count: 1}}, nil
+}
+
+func (qs *quotesStoreStub) GetById(id types.Id) (types.QuoteData, error) {
+	if id != 1 {
+		return types.QuoteData{}, ErrQuoteNotFound
+	}
+
+	return types.QuoteData{Id: id, Author: "Author", Quote: "Quote"}, nil
+}
+
+func (qs *quotesStoreStub) List(query types.QuotesQuery) ([]types.QuoteData, bool, error) {
+	quotes := []types.QuoteData{{Id: 1}, {Id: 2}, {Id: 3}}
+	if query.Author != "" || query.AuthorId != 0 {
+		quotes = quotes[:1]
+	}
+
+	if query.After != nil {
+		quotes = slices.DeleteFunc(quotes, func(quote types.QuoteData) bool {
+			return quote.Id <= query.After.Id
+		})
+	}
+
+	if query.Limit > 0 && query.Limit < len(quotes) {
+		return quotes[:query.Limit], true, nil
+	}
+
+	return quotes, false, nil
+}
+
+func (qs *quotesStoreStub) Search(query search.Query, limit int) ([]types.SearchHit, error) {
+	hits := []types.SearchHit{{Quote: types.QuoteData{Id: 1}}, {Quote: types.QuoteData{Id: 2}}}
+	return hits[:min(limit, len(hits))], nil
+}
+
+func (qs *quotesStoreStub) Update(id types.Id, modify func(quote *types.QuoteData) error) (types.QuoteData, error) {
+	if id != 1 {
+		return types.QuoteData{}, ErrQuoteNotFound
+	}
+
+	quote := types.QuoteData{Id: id, Author: "Author", Quote: "Quote"}
+	err := modify(&quote)
+	if err != nil {
+		return types.QuoteData{}, err
+	}
+
+	return quote, nil
+}
+
+func (qs *quotesStoreStub) Delete(id types.Id, versions types.Versions, actor string) error {
+	if !versions.Match(1) {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+func (qs *quotesStoreStub) GetTrash() ([]types.QuoteData, error) {
+	return make([]types.QuoteData, 1), nil
+}
+
+func (qs *quotesStoreStub) Restore(id types.Id, actor string) (types.QuoteData, error) {
+	if id != 1 {
+		return types.QuoteData{}, ErrQuoteNotFound
+	}
+
+	return types.QuoteData{Id: id, Author: "Author", Quote: "Quote", UpdatedBy: actor}, nil
+}
+
+func (qs *quotesStoreStub) Purge(id types.Id) error {
+	if id != 1 {
+		return ErrQuoteNotFound
+	}
+
 	return nil
 }
 
+func (qs *quotesStoreStub) GetRevisions(id types.Id) ([]types.Revision, error) {
+	if id != 1 {
+		return nil, ErrQuoteNotFound
+	}
+
+	return []types.Revision{
+		{QuoteId: id, Number: 1, Author: "Author", Quote: "Quote1"},
+		{QuoteId: id, Number: 2, Author: "Author", Quote: "Quote2", Tags: []types.Tag{"life"}},
+		{QuoteId: id, Number: 3, Author: "Author", Quote: "Quote2"},
+	}, nil
+}
+
+func (qs *quotesStoreStub) PurgeTrash(deletedBefore time.Time) (int, error) {
+	if deletedBefore.IsZero() {
+		return 2, nil
+	}
+
+	return 1, nil
+}
+
+// GetDuplicates reports the threshold it is called with as the similarity.
+func (qs *quotesStoreStub) GetDuplicates(id types.Id, threshold float64) ([]types.Duplicate, error) {
+	if id != 1 {
+		return nil, ErrQuoteNotFound
+	}
+
+	return []types.Duplicate{{Quote: types.QuoteData{Id: 2, Author: "Author", Quote: "Quote"}, Similarity: threshold}}, nil
+}
+
 func TestCreate(t *testing.T) {
 	quotesService := NewQuotesService(&quotesStoreStub{})
 
+	type output struct {
+		Id  types.Id
+		Err error
+	}
+
 	testCases := []struct {
 		name     string
 		input    types.CreateQuoteRequest
-		expected types.CreateQuoteResponse
+		expected output
 	}{
 		{
 			name:     "EmptyRequest",
 			input:    types.CreateQuoteRequest{},
-			expected: types.CreateQuoteResponse{Ok: false, Message: "author cannot be empty"},
+			expected: output{Id: 0, Err: ErrInvalidInput},
 		},
 		{
 			name:     "EmptyAuthor",
 			input:    types.CreateQuoteRequest{Quote: "Quote"},
-			expected: types.CreateQuoteResponse{Ok: false, Message: "author cannot be empty"},
+			expected: output{Id: 0, Err: ErrInvalidInput},
+		},
+		{
+			name:     "BlankAuthor",
+			input:    types.CreateQuoteRequest{Author: " \t ", Quote: "Quote"},
+			expected: output{Id: 0, Err: ErrInvalidInput},
 		},
 		{
 			name:     "EmptyQuote",
 			input:    types.CreateQuoteRequest{Author: "Author"},
-			expected: types.CreateQuoteResponse{Ok: false, Message: "quote cannot be empty"},
+			expected: output{Id: 0, Err: ErrInvalidInput},
+		},
+		{
+			name:     "InvalidTag",
+			input:    types.CreateQuoteRequest{Author: "Author", Quote: "Quote", Tags: []types.Tag{"two words"}},
+			expected: output{Id: 0, Err: ErrInvalidInput},
+		},
+		{
+			name:     "RelativeSourceURL",
+			input:    types.CreateQuoteRequest{Author: "Author", Quote: "Quote", Source: &types.Source{URL: "/books/1"}},
+			expected: output{Id: 0, Err: ErrInvalidInput},
+		},
+		{
+			name:     "NegativeSourcePage",
+			input:    types.CreateQuoteRequest{Author: "Author", Quote: "Quote", Source: &types.Source{Page: -1}},
+			expected: output{Id: 0, Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownAttribution",
+			input:    types.CreateQuoteRequest{Author: "Author", Quote: "Quote", Attribution: "apocryphal"},
+			expected: output{Id: 0, Err: ErrInvalidInput},
 		},
 		{
 			name:     "CorrectRequest",
 			input:    types.CreateQuoteRequest{Author: "Author", Quote: "Quote"},
-			expected: types.CreateQuoteResponse{Ok: true, Id: 1},
+			expected: output{Id: 1, Err: nil},
+		},
+		{
+			name: "CitedRequest",
+			input: types.CreateQuoteRequest{
+				Author:      "Author",
+				Quote:       "Quote",
+				Source:      &types.Source{Title: "Book", Chapter: "II", Page: 42, Year: 1869, URL: "https://example.com/book"},
+				Attribution: types.AttributionVerified,
+			},
+			expected: output{Id: 1, Err: nil},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := quotesService.Create(tc.input)
-			if got != tc.expected {
-				t.Errorf("service returned unexpected response: got %v want %v", got, tc.expected)
+			id, err := quotesService.Create(tc.input)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if id != tc.expected.Id {
+				t.Errorf("service returned unexpected id: got %v want %v", id, tc.expected.Id)
 			}
 		})
 	}
+
+	t.Run("TagsNormalized", func(t *testing.T) {
+		quotesStore := &quotesStoreStub{}
+		_, err := NewQuotesService(quotesStore).Create(types.CreateQuoteRequest{
+			Author: "Author",
+			Quote:  "Quote",
+			Tags:   []types.Tag{" Love ", "life", "love"},
+		})
+		if err != nil {
+			t.Errorf("service returned unexpected error: %v", err)
+		}
+
+		expected := []types.Tag{"life", "love"}
+		if !slices.Equal(quotesStore.created.Tags, expected) {
+			t.Errorf("service stored unexpected tags: got %v want %v", quotesStore.created.Tags, expected)
+		}
+	})
+
+	t.Run("ValidationMessage", func(t *testing.T) {
+		_, err := quotesService.Create(types.CreateQuoteRequest{Author: "Author"})
+		if err == nil || err.Error() != "quote cannot be empty" {
+			t.Errorf("service returned unexpected error: got %v want %v", err, "quote cannot be empty")
+		}
+	})
 }
 
 func TestGet(t *testing.T) {
 	quotesService := NewQuotesService(&quotesStoreStub{})
 
+	type output struct {
+		Page types.QuotesPage
+		Err  error
+	}
+
 	testCases := []struct {
 		name     string
-		input    types.Author
-		expected types.GetQuotesResponse
+		input    types.GetQuotesRequest
+		expected output
 	}{
 		{
 			name:     "EmptyAuthor",
-			input:    types.Author(""),
-			expected: types.GetQuotesResponse{Ok: true, Quotes: make([]types.QuoteData, 2)},
+			input:    types.GetQuotesRequest{},
+			expected: output{Page: types.QuotesPage{Quotes: []types.QuoteData{{Id: 1}, {Id: 2}, {Id: 3}}}},
 		},
 		{
 			name:     "SpecifiedAuthor",
-			input:    types.Author("Author"),
-			expected: types.GetQuotesResponse{Ok: true, Quotes: make([]types.QuoteData, 1)},
+			input:    types.GetQuotesRequest{Author: "Author"},
+			expected: output{Page: types.QuotesPage{Quotes: []types.QuoteData{{Id: 1}}}},
+		},
+		{
+			name:     "FirstPage",
+			input:    types.GetQuotesRequest{Limit: 2},
+			expected: output{Page: types.QuotesPage{Quotes: []types.QuoteData{{Id: 1}, {Id: 2}}, NextCursor: types.Cursor{Sort: "id", Id: 2}.String()}},
+		},
+		{
+			name:     "LastPage",
+			input:    types.GetQuotesRequest{Limit: 2, Cursor: types.Cursor{Sort: "id", Id: 2}.String()},
+			expected: output{Page: types.QuotesPage{Quotes: []types.QuoteData{{Id: 3}}}},
+		},
+		{
+			name:     "MalformedCursor",
+			input:    types.GetQuotesRequest{Limit: 2, Cursor: "abc"},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "AnotherSortCursor",
+			input:    types.GetQuotesRequest{Sort: "-id", Limit: 2, Cursor: types.Cursor{Sort: "id", Id: 2}.String()},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownSort",
+			input:    types.GetQuotesRequest{Sort: "quote"},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "LimitTooLarge",
+			input:    types.GetQuotesRequest{Limit: types.MaxPageLimit + 1},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownAttribution",
+			input:    types.GetQuotesRequest{Attribution: "apocryphal"},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownAuthorMatch",
+			input:    types.GetQuotesRequest{Author: "Author", AuthorMatch: "fuzzy"},
+			expected: output{Err: ErrInvalidInput},
 		},
 	}
 
-	responsesEqual := func(got, expected types.GetQuotesResponse) bool {
-		if got.Ok != expected.Ok {
-			return false
-		}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := quotesService.Get(tc.input)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(page.Quotes, tc.expected.Page.Quotes) {
+				t.Errorf("service returned unexpected quotes: got %v want %v", page.Quotes, tc.expected.Page.Quotes)
+			}
+			if page.NextCursor != tc.expected.Page.NextCursor {
+				t.Errorf("service returned unexpected cursor: got %v want %v", page.NextCursor, tc.expected.Page.NextCursor)
+			}
+		})
+	}
+}
 
-		if got.Message != expected.Message {
-			return false
-		}
+func TestSearch(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
 
-		if !slices.Equal(got.Quotes, expected.Quotes) {
-			return false
-		}
+	type output struct {
+		Count int
+		Err   error
+	}
 
-		return true
+	testCases := []struct {
+		name     string
+		input    types.SearchQuotesRequest
+		expected output
+	}{
+		{
+			name:     "EmptyQuery",
+			input:    types.SearchQuotesRequest{Query: "  "},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "NoWords",
+			input:    types.SearchQuotesRequest{Query: `"" *`},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "LimitTooLarge",
+			input:    types.SearchQuotesRequest{Query: "love", Limit: types.MaxSearchLimit + 1},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "DefaultLimit",
+			input:    types.SearchQuotesRequest{Query: "love"},
+			expected: output{Count: 2},
+		},
+		{
+			name:     "SpecifiedLimit",
+			input:    types.SearchQuotesRequest{Query: "love", Limit: 1},
+			expected: output{Count: 1},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := quotesService.Get(tc.input)
-			if !responsesEqual(got, tc.expected) {
-				t.Errorf("service returned unexpected response: got %v want %v", got, tc.expected)
+			results, err := quotesService.Search(tc.input)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if len(results) != tc.expected.Count {
+				t.Errorf("service returned unexpected number of results: got %v want %v", len(results), tc.expected.Count)
 			}
 		})
 	}
@@ -120,49 +388,613 @@ func TestGetRandom(t *testing.T) {
 
 	testCases := []struct {
 		name     string
-		expected types.GetRandomQuoteResponse
+		expected types.QuoteData
 	}{
 		{
 			name:     "GetRandom",
-			expected: types.GetRandomQuoteResponse{Ok: true, Quote: types.QuoteData{}},
+			expected: types.QuoteData{},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := quotesService.GetRandom()
-			if got != tc.expected {
-				t.Errorf("service returned unexpected response: got %v want %v", got, tc.expected)
+			quote, err := quotesService.GetRandom()
+			if err != nil {
+				t.Errorf("service returned unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(quote, tc.expected) {
+				t.Errorf("service returned unexpected quote: got %v want %v", quote, tc.expected)
 			}
 		})
 	}
 }
 
-func TestDelete(t *testing.T) {
+func TestGetById(t *testing.T) {
 	quotesService := NewQuotesService(&quotesStoreStub{})
 
+	type output struct {
+		Quote types.QuoteData
+		Err   error
+	}
+
 	testCases := []struct {
 		name     string
 		input    types.Id
-		expected types.DeleteQuoteResponse
+		expected output
 	}{
 		{
 			name:     "ZeroId",
 			input:    0,
-			expected: types.DeleteQuoteResponse{Ok: false, Message: "id cannot be zero"},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownId",
+			input:    2,
+			expected: output{Err: ErrQuoteNotFound},
+		},
+		{
+			name:     "CorrectId",
+			input:    1,
+			expected: output{Quote: types.QuoteData{Id: 1, Author: "Author", Quote: "Quote"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := quotesService.GetById(tc.input)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(quote, tc.expected.Quote) {
+				t.Errorf("service returned unexpected quote: got %v want %v", quote, tc.expected.Quote)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
+
+	type input struct {
+		Id      types.Id
+		Request types.UpdateQuoteRequest
+	}
+
+	type output struct {
+		Quote types.QuoteData
+		Err   error
+	}
+
+	testCases := []struct {
+		name     string
+		input    input
+		expected output
+	}{
+		{
+			name:     "ZeroId",
+			input:    input{Id: 0, Request: types.UpdateQuoteRequest{Author: "NewAuthor", Quote: "NewQuote"}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "EmptyAuthor",
+			input:    input{Id: 1, Request: types.UpdateQuoteRequest{Quote: "NewQuote"}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "EmptyQuote",
+			input:    input{Id: 1, Request: types.UpdateQuoteRequest{Author: "NewAuthor"}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownId",
+			input:    input{Id: 2, Request: types.UpdateQuoteRequest{Author: "NewAuthor", Quote: "NewQuote"}},
+			expected: output{Err: ErrQuoteNotFound},
+		},
+		{
+			name:     "StaleVersion",
+			input:    input{Id: 1, Request: types.UpdateQuoteRequest{Author: "NewAuthor", Quote: "NewQuote", Versions: types.Versions{2}}},
+			expected: output{Err: ErrVersionMismatch},
+		},
+		{
+			name:     "CorrectRequest",
+			input:    input{Id: 1, Request: types.UpdateQuoteRequest{Author: "NewAuthor", Quote: "NewQuote"}},
+			expected: output{Quote: types.QuoteData{Id: 1, Author: "NewAuthor", Quote: "NewQuote"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := quotesService.Update(tc.input.Id, tc.input.Request)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(quote, tc.expected.Quote) {
+				t.Errorf("service returned unexpected quote: got %v want %v", quote, tc.expected.Quote)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
+
+	var (
+		newAuthor types.Author      = "NewAuthor"
+		newQuote  types.Quote       = "NewQuote"
+		empty     types.Quote       = ""
+		disputed                    = types.AttributionDisputed
+		unknown   types.Attribution = "apocryphal"
+		title                       = " Analects "
+		year                        = -400
+	)
+
+	type input struct {
+		Id      types.Id
+		Request types.PatchQuoteRequest
+	}
+
+	type output struct {
+		Quote types.QuoteData
+		Err   error
+	}
+
+	testCases := []struct {
+		name     string
+		input    input
+		expected output
+	}{
+		{
+			name:     "ZeroId",
+			input:    input{Id: 0, Request: types.PatchQuoteRequest{Author: &newAuthor}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "EmptyQuote",
+			input:    input{Id: 1, Request: types.PatchQuoteRequest{Quote: &empty}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownId",
+			input:    input{Id: 2, Request: types.PatchQuoteRequest{Author: &newAuthor}},
+			expected: output{Err: ErrQuoteNotFound},
+		},
+		{
+			name:     "StaleVersion",
+			input:    input{Id: 1, Request: types.PatchQuoteRequest{Author: &newAuthor, Versions: types.Versions{2}}},
+			expected: output{Err: ErrVersionMismatch},
+		},
+		{
+			name:     "EmptyPatch",
+			input:    input{Id: 1, Request: types.PatchQuoteRequest{}},
+			expected: output{Quote: types.QuoteData{Id: 1, Author: "Author", Quote: "Quote"}},
+		},
+		{
+			name:     "AuthorOnly",
+			input:    input{Id: 1, Request: types.PatchQuoteRequest{Author: &newAuthor}},
+			expected: output{Quote: types.QuoteData{Id: 1, Author: newAuthor, Quote: "Quote"}},
+		},
+		{
+			name:     "AuthorAndQuote",
+			input:    input{Id: 1, Request: types.PatchQuoteRequest{Author: &newAuthor, Quote: &newQuote}},
+			expected: output{Quote: types.QuoteData{Id: 1, Author: newAuthor, Quote: newQuote}},
+		},
+		{
+			name:     "UnknownAttribution",
+			input:    input{Id: 1, Request: types.PatchQuoteRequest{Attribution: &unknown}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:  "SourceAndAttribution",
+			input: input{Id: 1, Request: types.PatchQuoteRequest{Source: &types.PatchSourceRequest{Title: &title, Year: &year}, Attribution: &disputed}},
+			expected: output{Quote: types.QuoteData{
+				Id:          1,
+				Author:      "Author",
+				Quote:       "Quote",
+				Source:      &types.Source{Title: "Analects", Year: -400},
+				Attribution: disputed,
+			}},
+		},
+		{
+			name:     "EmptySource",
+			input:    input{Id: 1, Request: types.PatchQuoteRequest{Source: &types.PatchSourceRequest{}}},
+			expected: output{Quote: types.QuoteData{Id: 1, Author: "Author", Quote: "Quote"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := quotesService.Patch(tc.input.Id, tc.input.Request)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(quote, tc.expected.Quote) {
+				t.Errorf("service returned unexpected quote: got %v want %v", quote, tc.expected.Quote)
+			}
+		})
+	}
+}
+
+func TestPatchSource(t *testing.T) {
+	quotesStore := stores.NewQuotesStore()
+	quotesService := NewQuotesService(quotesStore)
+	source := &types.Source{Title: "Analects", Chapter: "II", Page: 4, URL: "https://example.com/analects"}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected *types.Source
+	}{
+		{
+			name:     "OneField",
+			input:    `{"source":{"page":5}}`,
+			expected: &types.Source{Title: "Analects", Chapter: "II", Page: 5, URL: "https://example.com/analects"},
+		},
+		{
+			name:     "RemovedField",
+			input:    `{"source":{"chapter":null,"year":-400}}`,
+			expected: &types.Source{Title: "Analects", Page: 4, Year: -400, URL: "https://example.com/analects"},
+		},
+		{
+			name:     "EmptyPatch",
+			input:    `{"source":{}}`,
+			expected: source,
+		},
+		{
+			name:     "RemovedSource",
+			input:    `{"source":null}`,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: types.Quote(tc.name), Source: source})
+			if err != nil {
+				t.Fatalf("store returned unexpected error: %v", err)
+			}
+
+			request := types.PatchQuoteRequest{}
+			err = json.Unmarshal([]byte(tc.input), &request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			quote, err := quotesService.Patch(id, request)
+			if err != nil {
+				t.Errorf("service returned unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(quote.Source, tc.expected) {
+				t.Errorf("service returned unexpected source: got %v want %v", quote.Source, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
+
+	type input struct {
+		Id      types.Id
+		Request types.DeleteQuoteRequest
+	}
+
+	testCases := []struct {
+		name     string
+		input    input
+		expected error
+	}{
+		{
+			name:     "ZeroId",
+			input:    input{Id: 0},
+			expected: ErrInvalidInput,
+		},
+		{
+			name:     "LongActor",
+			input:    input{Id: 2, Request: types.DeleteQuoteRequest{Actor: strings.Repeat("a", 65)}},
+			expected: ErrInvalidInput,
+		},
+		{
+			name:     "StaleVersion",
+			input:    input{Id: 2, Request: types.DeleteQuoteRequest{Versions: types.Versions{2}}},
+			expected: ErrVersionMismatch,
 		},
 		{
 			name:     "CorrectId",
+			input:    input{Id: 2, Request: types.DeleteQuoteRequest{Actor: "alice"}},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := quotesService.Delete(tc.input.Id, tc.input.Request)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
+
+	type output struct {
+		Quote types.QuoteData
+		Err   error
+	}
+
+	type input struct {
+		Id      types.Id
+		Request types.RestoreQuoteRequest
+	}
+
+	testCases := []struct {
+		name     string
+		input    input
+		expected output
+	}{
+		{
+			name:     "ZeroId",
+			input:    input{Id: 0},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "LongActor",
+			input:    input{Id: 1, Request: types.RestoreQuoteRequest{Actor: strings.Repeat("a", 65)}},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownId",
+			input:    input{Id: 2},
+			expected: output{Err: ErrQuoteNotFound},
+		},
+		{
+			name:     "CorrectId",
+			input:    input{Id: 1, Request: types.RestoreQuoteRequest{Actor: "alice"}},
+			expected: output{Quote: types.QuoteData{Id: 1, Author: "Author", Quote: "Quote", UpdatedBy: "alice"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := quotesService.Restore(tc.input.Id, tc.input.Request)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(quote, tc.expected.Quote) {
+				t.Errorf("service returned unexpected quote: got %v want %v", quote, tc.expected.Quote)
+			}
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
+
+	testCases := []struct {
+		name     string
+		input    types.Id
+		expected error
+	}{
+		{
+			name:     "ZeroId",
+			input:    0,
+			expected: ErrInvalidInput,
+		},
+		{
+			name:     "UnknownId",
 			input:    2,
-			expected: types.DeleteQuoteResponse{Ok: true},
+			expected: ErrQuoteNotFound,
+		},
+		{
+			name:     "CorrectId",
+			input:    1,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := quotesService.Purge(tc.input)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDiffRevisions(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
+
+	type output struct {
+		Diff types.RevisionDiff
+		Err  error
+	}
+
+	testCases := []struct {
+		name     string
+		id       types.Id
+		input    types.DiffRevisionsRequest
+		expected output
+	}{
+		{
+			name:     "ZeroId",
+			id:       0,
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "NegativeRevision",
+			id:       1,
+			input:    types.DiffRevisionsRequest{From: -1},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownRevision",
+			id:       1,
+			input:    types.DiffRevisionsRequest{From: 1, To: 4},
+			expected: output{Err: ErrRevisionNotFound},
+		},
+		{
+			name:     "UnknownId",
+			id:       2,
+			expected: output{Err: ErrQuoteNotFound},
+		},
+		{
+			name:  "LatestChange",
+			id:    1,
+			input: types.DiffRevisionsRequest{},
+			expected: output{Diff: types.RevisionDiff{QuoteId: 1, From: 2, To: 3, Changes: []types.FieldChange{
+				{Field: "tags", From: []types.Tag{"life"}, To: []types.Tag(nil)},
+			}}},
+		},
+		{
+			name:  "SpecifiedRevisions",
+			id:    1,
+			input: types.DiffRevisionsRequest{From: 3, To: 1},
+			expected: output{Diff: types.RevisionDiff{QuoteId: 1, From: 3, To: 1, Changes: []types.FieldChange{
+				{Field: "quote", From: types.Quote("Quote2"), To: types.Quote("Quote1")},
+			}}},
+		},
+		{
+			name:     "SameRevision",
+			id:       1,
+			input:    types.DiffRevisionsRequest{From: 2, To: 2},
+			expected: output{Diff: types.RevisionDiff{QuoteId: 1, From: 2, To: 2, Changes: []types.FieldChange{}}},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := quotesService.Delete(tc.input)
-			if got != tc.expected {
-				t.Errorf("service returned unexpected response: got %v want %v", got, tc.expected)
+			diff, err := quotesService.DiffRevisions(tc.id, tc.input)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(diff, tc.expected.Diff) {
+				t.Errorf("service returned unexpected diff: got %v want %v", diff, tc.expected.Diff)
+			}
+		})
+	}
+}
+
+func TestRevert(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
+
+	type output struct {
+		Quote types.QuoteData
+		Err   error
+	}
+
+	testCases := []struct {
+		name     string
+		id       types.Id
+		input    types.RevertQuoteRequest
+		expected output
+	}{
+		{
+			name:     "ZeroRevision",
+			id:       1,
+			input:    types.RevertQuoteRequest{},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "LongActor",
+			id:       1,
+			input:    types.RevertQuoteRequest{To: 1, Actor: strings.Repeat("a", 65)},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownRevision",
+			id:       1,
+			input:    types.RevertQuoteRequest{To: 4},
+			expected: output{Err: ErrRevisionNotFound},
+		},
+		{
+			name:     "UnknownId",
+			id:       2,
+			input:    types.RevertQuoteRequest{To: 1},
+			expected: output{Err: ErrQuoteNotFound},
+		},
+		{
+			name:     "CorrectRevision",
+			id:       1,
+			input:    types.RevertQuoteRequest{To: 2, Actor: "alice"},
+			expected: output{Quote: types.QuoteData{Id: 1, Author: "Author", Quote: "Quote2", Tags: []types.Tag{"life"}, UpdatedBy: "alice"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := quotesService.Revert(tc.id, tc.input)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(quote, tc.expected.Quote) {
+				t.Errorf("service returned unexpected quote: got %v want %v", quote, tc.expected.Quote)
+			}
+		})
+	}
+}
+
+func TestGetDuplicates(t *testing.T) {
+	quotesService := NewQuotesService(&quotesStoreStub{})
+
+	type output struct {
+		Duplicates []types.Duplicate
+		Err        error
+	}
+	testCases := []struct {
+		name     string
+		id       types.Id
+		input    types.GetDuplicatesRequest
+		expected output
+	}{
+		{
+			name:     "InvalidId",
+			id:       0,
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "LowThreshold",
+			id:       1,
+			input:    types.GetDuplicatesRequest{Threshold: 0.3},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "HighThreshold",
+			id:       1,
+			input:    types.GetDuplicatesRequest{Threshold: 1.5},
+			expected: output{Err: ErrInvalidInput},
+		},
+		{
+			name:     "UnknownId",
+			id:       2,
+			expected: output{Err: ErrQuoteNotFound},
+		},
+		{
+			name: "DefaultThreshold",
+			id:   1,
+			expected: output{Duplicates: []types.Duplicate{
+				{Quote: types.QuoteData{Id: 2, Author: "Author", Quote: "Quote"}, Similarity: types.DefaultDuplicateThreshold},
+			}},
+		},
+		{
+			name:  "CustomThreshold",
+			id:    1,
+			input: types.GetDuplicatesRequest{Threshold: 0.9},
+			expected: output{Duplicates: []types.Duplicate{
+				{Quote: types.QuoteData{Id: 2, Author: "Author", Quote: "Quote"}, Similarity: 0.9},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			duplicates, err := quotesService.GetDuplicates(tc.id, tc.input)
+			if !errors.Is(err, tc.expected.Err) {
+				t.Errorf("service returned unexpected error: got %v want %v", err, tc.expected.Err)
+			}
+			if !reflect.DeepEqual(duplicates, tc.expected.Duplicates) {
+				t.Errorf("service returned unexpected duplicates: got %v want %v", duplicates, tc.expected.Duplicates)
 			}
 		})
 	}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"log"
+	"time"
+)
+
+// CollectTrash purges quotes which have been in the trash for longer than
+// retention every interval until stop is closed.
+func CollectTrash(quotesService QuotesService, retention time.Duration, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			purged, err := quotesService.PurgeTrash(now.Add(-retention))
+			if err != nil {
+				log.Printf("Failed to purge trash: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Purged %d quotes from trash", purged)
+			}
+		}
+	}
+}
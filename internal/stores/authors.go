@@ -0,0 +1,277 @@
+package stores
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+var (
+	ErrAuthorNotFound = errors.New("no author with specified id")
+	ErrAuthorConflict = errors.New("name is already used by another author")
+)
+
+type AuthorsStore interface {
+	// GetAuthors returns all authors in ascending order of ids.
+	GetAuthors() ([]types.AuthorData, error)
+	GetAuthorById(id types.Id) (types.AuthorData, error)
+	// SuggestAuthors returns at most limit authors having a word of the name
+	// or of an alias starting with prefix, the authors of most quotes first.
+	// Authors whose quotes are all deleted are not suggested.
+	// Words in another script match with AuthorMatchTranslit.
+	SuggestAuthors(prefix types.Author, match types.AuthorMatch, limit int) ([]types.AuthorData, error)
+	// UpdateAuthor atomically applies modify to the author with specified id
+	// and saves the result. Names and aliases of different authors cannot
+	// match, ErrAuthorConflict is returned otherwise.
+	UpdateAuthor(id types.Id, modify func(author *types.AuthorData) error) (types.AuthorData, error)
+	// MergeAuthors moves quotes of the author with specified id to the author
+	// into and removes the former. Its name and aliases become aliases of
+	// into, so they keep matching the quotes. actor is saved as the last one
+	// who changed the moved quotes. Quotes both authors have are kept, so
+	// they become exact duplicates reported by GetDuplicates.
+	MergeAuthors(id types.Id, into types.Id, actor string) (types.AuthorData, error)
+}
+
+// Store keeps quotes together with their authors, so a quote and a new author
+// it refers to are saved atomically.
+type Store interface {
+	QuotesStore
+	AuthorsStore
+}
+
+func (qs *quotesStore) GetAuthors() ([]types.AuthorData, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	authors := make([]types.AuthorData, 0, len(qs.authorIds))
+	for _, id := range qs.authorIds {
+		authors = append(authors, qs.author(id))
+	}
+
+	return authors, nil
+}
+
+func (qs *quotesStore) GetAuthorById(id types.Id) (types.AuthorData, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	_, ok := qs.authors[id]
+	if !ok {
+		return types.AuthorData{}, ErrAuthorNotFound
+	}
+
+	return qs.author(id), nil
+}
+
+func (qs *quotesStore) SuggestAuthors(prefix types.Author, match types.AuthorMatch, limit int) ([]types.AuthorData, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	key := prefix.Key()
+	if key == "" {
+		return []types.AuthorData{}, nil
+	}
+
+	ids := qs.names.lookup(key)
+	if match == types.AuthorMatchTranslit {
+		ids = unionIds(ids, qs.latinNames.lookup(prefix.TranslitKey()))
+	}
+	authors := make([]types.AuthorData, 0, len(ids))
+	for _, id := range ids {
+		// authors stay after their last quote is deleted, but there is
+		// nothing to find by them
+		author := qs.author(id)
+		if author.QuoteCount == 0 {
+			continue
+		}
+		authors = append(authors, author)
+	}
+	slices.SortFunc(authors, func(a, b types.AuthorData) int {
+		return cmp.Or(
+			cmp.Compare(b.QuoteCount, a.QuoteCount),
+			strings.Compare(string(a.Name.Key()), string(b.Name.Key())),
+			cmp.Compare(a.Id, b.Id),
+		)
+	})
+
+	if limit > 0 && len(authors) > limit {
+		authors = authors[:limit]
+	}
+
+	return authors, nil
+}
+
+func (qs *quotesStore) UpdateAuthor(id types.Id, modify func(author *types.AuthorData) error) (types.AuthorData, error) {
+	qs.mtx.Lock()
+	defer qs.mtx.Unlock()
+
+	author, ok := qs.authors[id]
+	if !ok {
+		return types.AuthorData{}, ErrAuthorNotFound
+	}
+
+	// modify must not change aliases of the stored author in place
+	author.Aliases = slices.Clone(author.Aliases)
+	err := modify(&author)
+	if err != nil {
+		return types.AuthorData{}, err
+	}
+	author.Id = id
+
+	for _, key := range author.Keys() {
+		other, ok := qs.authorKeys[key]
+		if ok && other != id {
+			return types.AuthorData{}, ErrAuthorConflict
+		}
+	}
+
+	err = qs.commit(change{CurrId: qs.currId, CurrAuthorId: qs.currAuthorId, PutAuthors: []types.AuthorData{author}})
+	if err != nil {
+		return types.AuthorData{}, err
+	}
+
+	return qs.author(id), nil
+}
+
+func (qs *quotesStore) MergeAuthors(id types.Id, into types.Id, actor string) (types.AuthorData, error) {
+	qs.mtx.Lock()
+	defer qs.mtx.Unlock()
+
+	source, ok := qs.authors[id]
+	if !ok {
+		return types.AuthorData{}, ErrAuthorNotFound
+	}
+	target, ok := qs.authors[into]
+	if !ok {
+		return types.AuthorData{}, ErrAuthorNotFound
+	}
+	if id == into {
+		return qs.author(into), nil
+	}
+
+	target.Aliases = append(slices.Clone(target.Aliases), source.Name)
+	target.Aliases = append(target.Aliases, source.Aliases...)
+	target = types.NormalizeAuthor(target)
+	// details known only for the merged author are not lost
+	if target.BirthYear == 0 {
+		target.BirthYear = source.BirthYear
+	}
+	if target.DeathYear == 0 {
+		target.DeathYear = source.DeathYear
+	}
+	if target.Bio == "" {
+		target.Bio = source.Bio
+	}
+
+	c := change{
+		CurrId:        qs.currId,
+		CurrAuthorId:  qs.currAuthorId,
+		DeleteAuthors: []types.Id{id},
+		PutAuthors:    []types.AuthorData{target},
+	}
+	quotes := make([]types.QuoteData, 0, len(qs.byAuthor[id]))
+	for _, quoteId := range qs.byAuthor[id] {
+		quotes = append(quotes, qs.quotes[qs.positions[quoteId]])
+	}
+	// quotes in the trash must not refer to a removed author once restored
+	for _, quoteId := range qs.trashIds {
+		if qs.trash[quoteId].AuthorId == id {
+			quotes = append(quotes, qs.trash[quoteId])
+		}
+	}
+
+	now := qs.now()
+	for _, old := range quotes {
+		quote := old
+		quote.Author = target.Name
+		quote.AuthorId = into
+		quote.UpdatedAt = now
+		quote.UpdatedBy = actor
+		quote.Version++
+		c.Put = append(c.Put, quote)
+		c.Revisions = append(c.Revisions, qs.revise(old, quote)...)
+	}
+
+	err := qs.commit(c)
+	if err != nil {
+		return types.AuthorData{}, err
+	}
+
+	return qs.author(into), nil
+}
+
+// author returns the author with specified id and the number of its quotes.
+func (qs *quotesStore) author(id types.Id) types.AuthorData {
+	author := qs.authors[id]
+	author.QuoteCount = len(qs.byAuthor[id])
+	return author
+}
+
+// authorFor returns the author named name or a new author if there is no such
+// author yet. Author ids cannot run out before quote ids, since authors are
+// only created along with quotes.
+func (qs *quotesStore) authorFor(name types.Author) (types.AuthorData, bool) {
+	id, ok := qs.authorKeys[name.Key()]
+	if ok {
+		return qs.authors[id], false
+	}
+
+	return types.AuthorData{
+		Id:   qs.currAuthorId + 1,
+		Name: types.Author(strings.Join(strings.Fields(string(name)), " ")),
+	}, true
+}
+
+// linkAuthor links quote to the author of its name. A new author is added to c.
+func (qs *quotesStore) linkAuthor(quote *types.QuoteData, c *change) {
+	author, created := qs.authorFor(quote.Author)
+	quote.AuthorId = author.Id
+	if created {
+		c.CurrAuthorId = author.Id
+		c.PutAuthors = append(c.PutAuthors, author)
+	}
+}
+
+func (qs *quotesStore) putAuthor(author types.AuthorData) {
+	old, ok := qs.authors[author.Id]
+	if ok {
+		qs.unindexAuthor(old)
+	} else {
+		qs.authorIds = insertId(qs.authorIds, author.Id)
+	}
+
+	author.QuoteCount = 0
+	qs.authors[author.Id] = author
+	for _, key := range author.Keys() {
+		qs.authorKeys[key] = author.Id
+		qs.names.add(key, author.Id)
+		qs.byTranslit.add(key.TranslitKey(), author.Id)
+		qs.latinNames.add(key.TranslitKey(), author.Id)
+	}
+}
+
+func (qs *quotesStore) removeAuthor(id types.Id) {
+	author, ok := qs.authors[id]
+	if !ok {
+		return
+	}
+
+	qs.unindexAuthor(author)
+	delete(qs.authors, id)
+	qs.authorIds = removeId(qs.authorIds, id)
+}
+
+// unindexAuthor removes keys of author which still belong to it.
+func (qs *quotesStore) unindexAuthor(author types.AuthorData) {
+	for _, key := range author.Keys() {
+		if qs.authorKeys[key] == author.Id {
+			delete(qs.authorKeys, key)
+		}
+		qs.names.remove(key, author.Id)
+		qs.byTranslit.remove(key.TranslitKey(), author.Id)
+		qs.latinNames.remove(key.TranslitKey(), author.Id)
+	}
+}
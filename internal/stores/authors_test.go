@@ -0,0 +1,419 @@
+package stores
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+func TestGetAuthors(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	t.Run("EmptyStore", func(t *testing.T) {
+		authors, err := quotesStore.GetAuthors()
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if len(authors) != 0 {
+			t.Errorf("store returned unexpected authors: %v", authors)
+		}
+	})
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: " Лев  Толстой ", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "Quote2"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "лев толстой", Quote: "Quote3"})
+
+	t.Run("QuotesLinked", func(t *testing.T) {
+		for id, authorId := range map[types.Id]types.Id{id1: 1, id2: 2, id3: 1} {
+			quote, _ := quotesStore.GetById(id)
+			if quote.AuthorId != authorId {
+				t.Errorf("store linked quote %v to unexpected author: got %v want %v", id, quote.AuthorId, authorId)
+			}
+		}
+	})
+
+	expectedAuthors := []types.AuthorData{
+		{Id: 1, Name: "Лев Толстой", QuoteCount: 2},
+		{Id: 2, Name: "Confucius", QuoteCount: 1},
+	}
+	t.Run("AuthorsCreated", func(t *testing.T) {
+		authors, err := quotesStore.GetAuthors()
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(authors, expectedAuthors) {
+			t.Errorf("store returned unexpected authors: got %v want %v", authors, expectedAuthors)
+		}
+	})
+
+	quotesStore.Delete(id2, nil, "")
+	t.Run("AuthorKeptAfterDelete", func(t *testing.T) {
+		author, err := quotesStore.GetAuthorById(2)
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if author.QuoteCount != 0 {
+			t.Errorf("store returned unexpected quote count: got %v want %v", author.QuoteCount, 0)
+		}
+	})
+
+	t.Run("WrongId", func(t *testing.T) {
+		_, err := quotesStore.GetAuthorById(50)
+		if err != ErrAuthorNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrAuthorNotFound)
+		}
+	})
+}
+
+func TestUpdateAuthor(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Lev Tolstoy", Quote: "Quote1"})
+	quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "Quote2"})
+
+	t.Run("WrongId", func(t *testing.T) {
+		_, err := quotesStore.UpdateAuthor(50, func(author *types.AuthorData) error {
+			return nil
+		})
+		if err != ErrAuthorNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrAuthorNotFound)
+		}
+	})
+
+	t.Run("FailedModification", func(t *testing.T) {
+		expectedError := fmt.Errorf("modification failed")
+		_, err := quotesStore.UpdateAuthor(1, func(author *types.AuthorData) error {
+			author.Bio = "Bio"
+			return expectedError
+		})
+		if err != expectedError {
+			t.Errorf("store returned unexpected error: got %v want %v", err, expectedError)
+		}
+		if quotesStore.authors[1].Bio != "" {
+			t.Errorf("store saved failed modification: %v", quotesStore.authors[1])
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := quotesStore.UpdateAuthor(1, func(author *types.AuthorData) error {
+			author.Aliases = []types.Author{"CONFUCIUS"}
+			return nil
+		})
+		if err != ErrAuthorConflict {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrAuthorConflict)
+		}
+	})
+
+	expectedAuthor := types.AuthorData{
+		Id:         1,
+		Name:       "Лев Толстой",
+		Aliases:    []types.Author{"Lev Tolstoy"},
+		BirthYear:  1828,
+		DeathYear:  1910,
+		QuoteCount: 1,
+	}
+	t.Run("CorrectId", func(t *testing.T) {
+		author, err := quotesStore.UpdateAuthor(1, func(author *types.AuthorData) error {
+			author.Id = 42
+			author.Name = "Лев Толстой"
+			author.Aliases = []types.Author{"Lev Tolstoy"}
+			author.BirthYear = 1828
+			author.DeathYear = 1910
+			return nil
+		})
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(author, expectedAuthor) {
+			t.Errorf("store returned unexpected author: got %v want %v", author, expectedAuthor)
+		}
+	})
+
+	t.Run("AliasesMatch", func(t *testing.T) {
+		id2, _ := quotesStore.Create(types.QuoteData{Author: "lev tolstoy", Quote: "Quote3"})
+		id3, _ := quotesStore.Create(types.QuoteData{Author: "Лев Толстой", Quote: "Quote4"})
+
+		for _, name := range []types.Author{"Лев Толстой", "Lev Tolstoy"} {
+			quotes, _ := quotesStore.GetByAuthor(name)
+			ids := make([]types.Id, 0, len(quotes))
+			for _, quote := range quotes {
+				ids = append(ids, quote.Id)
+			}
+			if !slices.Equal(ids, []types.Id{id1, id2, id3}) {
+				t.Errorf("store returned unexpected quotes for %v: got %v want %v", name, ids, []types.Id{id1, id2, id3})
+			}
+		}
+	})
+
+	t.Run("ListByAuthorId", func(t *testing.T) {
+		quotes, _, _ := quotesStore.List(types.QuotesQuery{AuthorId: 2})
+		if len(quotes) != 1 || quotes[0].AuthorId != 2 {
+			t.Errorf("store returned unexpected quotes: %v", quotes)
+		}
+	})
+}
+
+func TestMergeAuthors(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Конфуций", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "Quote2"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Kong Fuzi", Quote: "Quote3"})
+	quotesStore.Create(types.QuoteData{Author: "Laozi", Quote: "Quote4"})
+	quotesStore.UpdateAuthor(2, func(author *types.AuthorData) error {
+		author.BirthYear = -551
+		author.Aliases = []types.Author{"Kongzi"}
+		return nil
+	})
+
+	t.Run("UnknownId", func(t *testing.T) {
+		_, err := quotesStore.MergeAuthors(50, 1, "")
+		if err != ErrAuthorNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrAuthorNotFound)
+		}
+	})
+
+	expectedAuthor := types.AuthorData{
+		Id:         1,
+		Name:       "Конфуций",
+		Aliases:    []types.Author{"Confucius", "Kongzi", "Kong Fuzi"},
+		BirthYear:  -551,
+		QuoteCount: 3,
+	}
+	t.Run("Merged", func(t *testing.T) {
+		quotesStore.MergeAuthors(2, 1, "")
+		author, err := quotesStore.MergeAuthors(3, 1, "")
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(author, expectedAuthor) {
+			t.Errorf("store returned unexpected author: got %v want %v", author, expectedAuthor)
+		}
+
+		_, err = quotesStore.GetAuthorById(2)
+		if err != ErrAuthorNotFound {
+			t.Errorf("store kept merged author: got %v want %v", err, ErrAuthorNotFound)
+		}
+	})
+
+	t.Run("QuotesRewritten", func(t *testing.T) {
+		for _, id := range []types.Id{id2, id3} {
+			quote, _ := quotesStore.GetById(id)
+			if quote.AuthorId != 1 || quote.Author != "Конфуций" {
+				t.Errorf("store returned unexpected quote: %v", quote)
+			}
+		}
+	})
+
+	t.Run("AliasesMatch", func(t *testing.T) {
+		for _, name := range []types.Author{"Конфуций", "confucius", "Kongzi", "KONG FUZI"} {
+			quotes, _ := quotesStore.GetByAuthor(name)
+			ids := make([]types.Id, 0, len(quotes))
+			for _, quote := range quotes {
+				ids = append(ids, quote.Id)
+			}
+			if !slices.Equal(ids, []types.Id{id1, id2, id3}) {
+				t.Errorf("store returned unexpected quotes for %v: got %v want %v", name, ids, []types.Id{id1, id2, id3})
+			}
+		}
+	})
+
+	t.Run("NewQuotesLinked", func(t *testing.T) {
+		id, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "Quote5"})
+		quote, _ := quotesStore.GetById(id)
+		if quote.AuthorId != 1 {
+			t.Errorf("store linked quote to unexpected author: got %v want %v", quote.AuthorId, 1)
+		}
+	})
+}
+
+func TestSuggestAuthors(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	quotesStore.Create(types.QuoteData{Author: "Лев Толстой", Quote: "Quote1"})
+	quotesStore.Create(types.QuoteData{Author: "Алексей Толстой", Quote: "Quote2"})
+	quotesStore.Create(types.QuoteData{Author: "Алексей Толстой", Quote: "Quote3"})
+	quotesStore.Create(types.QuoteData{Author: "Фёдор Тютчев", Quote: "Quote4"})
+	quotesStore.Create(types.QuoteData{Author: "Thomas Mann", Quote: "Quote5"})
+	quotesStore.Create(types.QuoteData{Author: "Tolkien", Quote: "Quote6"})
+	quotesStore.UpdateAuthor(1, func(author *types.AuthorData) error {
+		author.Aliases = []types.Author{"Leo Tolstoy"}
+		return nil
+	})
+
+	testCases := []struct {
+		name     string
+		prefix   types.Author
+		match    types.AuthorMatch
+		limit    int
+		expected []types.Id
+	}{
+		{
+			name:     "RankedByQuotes",
+			prefix:   "толст",
+			match:    types.AuthorMatchExact,
+			expected: []types.Id{2, 1},
+		},
+		{
+			name:     "FirstWord",
+			prefix:   "ЛЕВ",
+			match:    types.AuthorMatchExact,
+			expected: []types.Id{1},
+		},
+		{
+			name:     "SeveralWords",
+			prefix:   "лев тол",
+			match:    types.AuthorMatchExact,
+			expected: []types.Id{1},
+		},
+		{
+			name:     "YoFolded",
+			prefix:   "федор",
+			match:    types.AuthorMatchExact,
+			expected: []types.Id{3},
+		},
+		{
+			name:     "Latin",
+			prefix:   "to",
+			match:    types.AuthorMatchExact,
+			expected: []types.Id{5, 1},
+		},
+		{
+			name:     "Limit",
+			prefix:   "т",
+			match:    types.AuthorMatchExact,
+			limit:    2,
+			expected: []types.Id{2, 1},
+		},
+		{
+			name:     "LatinPrefix",
+			prefix:   "Tolst",
+			match:    types.AuthorMatchTranslit,
+			expected: []types.Id{2, 1},
+		},
+		{
+			name:     "CyrillicPrefix",
+			prefix:   "манн",
+			match:    types.AuthorMatchTranslit,
+			expected: []types.Id{4},
+		},
+		{
+			name:     "NoMatch",
+			prefix:   "пушкин",
+			match:    types.AuthorMatchExact,
+			expected: []types.Id{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authors, err := quotesStore.SuggestAuthors(tc.prefix, tc.match, tc.limit)
+			if err != nil {
+				t.Errorf("store returned unexpected error: %v", err)
+			}
+			ids := make([]types.Id, 0, len(authors))
+			for _, author := range authors {
+				ids = append(ids, author.Id)
+			}
+			if !slices.Equal(ids, tc.expected) {
+				t.Errorf("store returned unexpected authors: got %v want %v", ids, tc.expected)
+			}
+		})
+	}
+
+	t.Run("AliasesReplaced", func(t *testing.T) {
+		quotesStore.UpdateAuthor(1, func(author *types.AuthorData) error {
+			author.Aliases = nil
+			return nil
+		})
+		authors, _ := quotesStore.SuggestAuthors("leo", types.AuthorMatchTranslit, 0)
+		if len(authors) != 0 {
+			t.Errorf("store returned unexpected authors: %v", authors)
+		}
+	})
+
+	t.Run("AuthorWithoutQuotes", func(t *testing.T) {
+		id, _ := quotesStore.Create(types.QuoteData{Author: "Typo Authr", Quote: "Quote7"})
+		quotesStore.Delete(id, nil, "")
+		authors, _ := quotesStore.SuggestAuthors("typo", types.AuthorMatchExact, 0)
+		if len(authors) != 0 {
+			t.Errorf("store returned unexpected authors: %v", authors)
+		}
+
+		quotesStore.Restore(id, "")
+		authors, _ = quotesStore.SuggestAuthors("typo", types.AuthorMatchExact, 0)
+		if len(authors) != 1 {
+			t.Errorf("store returned unexpected authors: %v", authors)
+		}
+
+		quotesStore.Delete(id, nil, "")
+		quotesStore.Purge(id)
+		authors, _ = quotesStore.SuggestAuthors("typo", types.AuthorMatchExact, 0)
+		if len(authors) != 0 {
+			t.Errorf("store returned unexpected authors: %v", authors)
+		}
+	})
+
+	t.Run("MergedAuthorRemoved", func(t *testing.T) {
+		quotesStore.MergeAuthors(4, 3, "")
+		authors, _ := quotesStore.SuggestAuthors("thomas", types.AuthorMatchExact, 0)
+		if len(authors) != 1 || authors[0].Id != 3 {
+			t.Errorf("store returned unexpected authors: %v", authors)
+		}
+	})
+}
+
+func TestListByAuthorTranslit(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Лев Толстой", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Lev Tolstoy", Quote: "Quote2"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Фёдор Достоевский", Quote: "Quote3"})
+	quotesStore.Create(types.QuoteData{Author: "Алексей Толстой", Quote: "Quote4"})
+
+	testCases := []struct {
+		name     string
+		input    types.QuotesQuery
+		expected []types.Id
+	}{
+		{
+			name:     "Exact",
+			input:    types.QuotesQuery{Author: "lev tolstoy", AuthorMatch: types.AuthorMatchExact},
+			expected: []types.Id{id2},
+		},
+		{
+			name:     "LatinQuery",
+			input:    types.QuotesQuery{Author: "Lev Tolstoj", AuthorMatch: types.AuthorMatchTranslit},
+			expected: []types.Id{id1, id2},
+		},
+		{
+			name:     "CyrillicQuery",
+			input:    types.QuotesQuery{Author: "лев толстой", AuthorMatch: types.AuthorMatchTranslit},
+			expected: []types.Id{id1, id2},
+		},
+		{
+			name:     "AnotherSpelling",
+			input:    types.QuotesQuery{Author: "Fyodor Dostoyevsky", AuthorMatch: types.AuthorMatchTranslit},
+			expected: []types.Id{id3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotes, _, err := quotesStore.List(tc.input)
+			if err != nil {
+				t.Errorf("store returned unexpected error: %v", err)
+			}
+			ids := make([]types.Id, 0, len(quotes))
+			for _, quote := range quotes {
+				ids = append(ids, quote.Id)
+			}
+			if !slices.Equal(ids, tc.expected) {
+				t.Errorf("store returned unexpected quotes: got %v want %v", ids, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package stores
+
+import (
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+// contentKey identifies quotes considered equal.
+type contentKey struct {
+	authorId types.Id
+	text     types.Quote
+}
+
+func contentKeyOf(quote types.QuoteData) contentKey {
+	return contentKey{authorId: quote.AuthorId, text: quote.Quote.Key()}
+}
+
+// duplicateOf returns the id of another live quote of the same author with
+// the same text as quote.
+func (qs *quotesStore) duplicateOf(quote types.QuoteData) (types.Id, bool) {
+	for _, id := range qs.byContent[contentKeyOf(quote)] {
+		if id != quote.Id {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+func (qs *quotesStore) GetDuplicates(id types.Id, threshold float64) ([]types.Duplicate, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	_, ok := qs.positions[id]
+	if !ok {
+		return nil, ErrQuoteNotFound
+	}
+
+	matches := qs.similar.Similar(id, threshold)
+	duplicates := make([]types.Duplicate, 0, len(matches))
+	for _, match := range matches {
+		duplicates = append(duplicates, types.Duplicate{
+			Quote:      qs.quotes[qs.positions[match.Id]],
+			Similarity: match.Similarity,
+		})
+	}
+
+	return duplicates, nil
+}
@@ -0,0 +1,109 @@
+package stores
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+var (
+	ErrIdempotencyKeyReused = errors.New("idempotency key has already been used for another request")
+	ErrRequestInProgress    = errors.New("request with the same idempotency key is in progress")
+)
+
+type IdempotencyStore interface {
+	// Begin reserves key for a request identified by fingerprint. It returns
+	// the response saved for the key if the request has been completed and
+	// nil if the request should be processed, which must be followed by
+	// Complete or Release.
+	Begin(key types.IdempotencyKey, fingerprint string) (*types.StoredResponse, error)
+	// Complete saves the response for the key, it is kept for the TTL of the
+	// store.
+	Complete(key types.IdempotencyKey, response types.StoredResponse)
+	// Release frees the key of a failed request, so it can be retried.
+	Release(key types.IdempotencyKey)
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	response    *types.StoredResponse // nil while the request is in progress
+	expiresAt   time.Time
+}
+
+// idempotencyStore keeps responses in memory. Since all responses live for
+// the same TTL, expiry holds keys of completed requests in order of
+// expiration and expired keys are removed from its front.
+type idempotencyStore struct {
+	mtx     sync.Mutex
+	ttl     time.Duration
+	entries map[types.IdempotencyKey]*idempotencyEntry
+	expiry  []types.IdempotencyKey
+	clock   func() time.Time
+}
+
+func NewIdempotencyStore(ttl time.Duration) IdempotencyStore {
+	return newIdempotencyStore(ttl)
+}
+
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:     ttl,
+		entries: make(map[types.IdempotencyKey]*idempotencyEntry),
+		clock:   time.Now,
+	}
+}
+
+func (is *idempotencyStore) Begin(key types.IdempotencyKey, fingerprint string) (*types.StoredResponse, error) {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+
+	is.expire()
+
+	entry, ok := is.entries[key]
+	if !ok {
+		is.entries[key] = &idempotencyEntry{fingerprint: fingerprint}
+		return nil, nil
+	}
+	if entry.fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if entry.response == nil {
+		return nil, ErrRequestInProgress
+	}
+
+	return entry.response, nil
+}
+
+func (is *idempotencyStore) Complete(key types.IdempotencyKey, response types.StoredResponse) {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+
+	entry, ok := is.entries[key]
+	if !ok || entry.response != nil {
+		return
+	}
+
+	entry.response = &response
+	entry.expiresAt = is.clock().Add(is.ttl)
+	is.expiry = append(is.expiry, key)
+}
+
+func (is *idempotencyStore) Release(key types.IdempotencyKey) {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+
+	entry, ok := is.entries[key]
+	if ok && entry.response == nil {
+		delete(is.entries, key)
+	}
+}
+
+func (is *idempotencyStore) expire() {
+	now := is.clock()
+	for len(is.expiry) > 0 && !is.entries[is.expiry[0]].expiresAt.After(now) {
+		delete(is.entries, is.expiry[0])
+		is.expiry = is.expiry[1:]
+	}
+}
@@ -0,0 +1,89 @@
+package stores
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	now := testTime
+	idempotencyStore := newIdempotencyStore(time.Hour)
+	idempotencyStore.clock = func() time.Time { return now }
+
+	response := types.StoredResponse{Status: 200, ContentType: "application/json", Body: []byte(`{"ok":true,"id":1}`)}
+
+	testCases := []struct {
+		name             string
+		prepare          func()
+		key              types.IdempotencyKey
+		fingerprint      string
+		expectedResponse *types.StoredResponse
+		expectedError    error
+	}{
+		{
+			name:        "NewKey",
+			key:         "key1",
+			fingerprint: "request1",
+		},
+		{
+			name:          "InProgress",
+			key:           "key1",
+			fingerprint:   "request1",
+			expectedError: ErrRequestInProgress,
+		},
+		{
+			name:             "Completed",
+			prepare:          func() { idempotencyStore.Complete("key1", response) },
+			key:              "key1",
+			fingerprint:      "request1",
+			expectedResponse: &response,
+		},
+		{
+			name:          "OtherRequest",
+			key:           "key1",
+			fingerprint:   "request2",
+			expectedError: ErrIdempotencyKeyReused,
+		},
+		{
+			name: "Released",
+			prepare: func() {
+				idempotencyStore.Begin("key2", "request1")
+				idempotencyStore.Release("key2")
+			},
+			key:         "key2",
+			fingerprint: "request2",
+		},
+		{
+			name:             "NotExpired",
+			prepare:          func() { now = now.Add(59 * time.Minute) },
+			key:              "key1",
+			fingerprint:      "request1",
+			expectedResponse: &response,
+		},
+		{
+			name:        "Expired",
+			prepare:     func() { now = now.Add(time.Minute) },
+			key:         "key1",
+			fingerprint: "request2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if testCase.prepare != nil {
+				testCase.prepare()
+			}
+
+			saved, err := idempotencyStore.Begin(testCase.key, testCase.fingerprint)
+			if err != testCase.expectedError {
+				t.Errorf("store returned unexpected error: got %v want %v", err, testCase.expectedError)
+			}
+			if !reflect.DeepEqual(saved, testCase.expectedResponse) {
+				t.Errorf("store returned unexpected response: got %v want %v", saved, testCase.expectedResponse)
+			}
+		})
+	}
+}
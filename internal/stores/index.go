@@ -0,0 +1,91 @@
+package stores
+
+import (
+	"slices"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+// insertId adds id to ids sorted in ascending order.
+func insertId(ids []types.Id, id types.Id) []types.Id {
+	// ids are assigned in ascending order, so usually this is an append
+	if len(ids) == 0 || ids[len(ids)-1] < id {
+		return append(ids, id)
+	}
+
+	pos, found := slices.BinarySearch(ids, id)
+	if found {
+		return ids
+	}
+
+	return slices.Insert(ids, pos, id)
+}
+
+// removeId removes id from ids sorted in ascending order.
+func removeId(ids []types.Id, id types.Id) []types.Id {
+	pos, found := slices.BinarySearch(ids, id)
+	if !found {
+		return ids
+	}
+
+	return slices.Delete(ids, pos, pos+1)
+}
+
+// idIndex maps a key to the ids of quotes having it, sorted in ascending order.
+type idIndex[K comparable] map[K][]types.Id
+
+func (idx idIndex[K]) add(key K, id types.Id) {
+	idx[key] = insertId(idx[key], id)
+}
+
+func (idx idIndex[K]) remove(key K, id types.Id) {
+	ids := removeId(idx[key], id)
+	if len(ids) == 0 {
+		delete(idx, key)
+		return
+	}
+
+	idx[key] = ids
+}
+
+// intersectIds returns ids present in both a and b, sorted in ascending order.
+func intersectIds(a, b []types.Id) []types.Id {
+	result := make([]types.Id, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+
+	return result
+}
+
+// unionIds returns ids present in a or b, sorted in ascending order.
+func unionIds(a, b []types.Id) []types.Id {
+	result := make([]types.Id, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+
+	return append(result, b[j:]...)
+}
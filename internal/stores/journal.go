@@ -0,0 +1,198 @@
+package stores
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	walFileName      = "quotes.wal"
+	snapshotFileName = "quotes.snapshot"
+)
+
+// fileJournal appends every change to a write-ahead log and periodically
+// replaces the log with a snapshot of the whole store.
+type fileJournal struct {
+	dir           string
+	snapshotEvery int
+	records       int
+	size          int64
+}
+
+// NewFileQuotesStore returns a store that keeps its data in dir. On startup
+// the last snapshot and the write-ahead log written after it are replayed.
+// A new snapshot is written after every snapshotEvery logged changes.
+func NewFileQuotesStore(dir string, snapshotEvery int, options ...Option) (Store, error) {
+	if snapshotEvery <= 0 {
+		return nil, fmt.Errorf("snapshot interval should be positive")
+	}
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	qs := newQuotesStore(options...)
+	fj := &fileJournal{dir: dir, snapshotEvery: snapshotEvery}
+
+	err = fj.replay(qs)
+	if err != nil {
+		return nil, err
+	}
+
+	qs.journal = fj
+	return qs, nil
+}
+
+func (fj *fileJournal) walPath() string {
+	return filepath.Join(fj.dir, walFileName)
+}
+
+func (fj *fileJournal) snapshotPath() string {
+	return filepath.Join(fj.dir, snapshotFileName)
+}
+
+func (fj *fileJournal) replay(qs *quotesStore) error {
+	snapshot, err := os.ReadFile(fj.snapshotPath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		c := change{}
+		err = json.Unmarshal(snapshot, &c)
+		if err != nil {
+			return fmt.Errorf("corrupted snapshot: %w", err)
+		}
+		qs.apply(c)
+	}
+
+	wal, err := os.Open(fj.walPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer wal.Close()
+
+	reader := bufio.NewReader(wal)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// a record without trailing newline is a torn write, the change
+			// was never acknowledged so it is dropped
+			if len(line) != 0 {
+				return os.Truncate(fj.walPath(), fj.size)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		c := change{}
+		err = json.Unmarshal(line, &c)
+		if err != nil {
+			return fmt.Errorf("corrupted write-ahead log at offset %d: %w", fj.size, err)
+		}
+		qs.apply(c)
+
+		fj.size += int64(len(line))
+		fj.records++
+	}
+}
+
+func (fj *fileJournal) record(c change) error {
+	line, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	wal, err := os.OpenFile(fj.walPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer wal.Close()
+
+	_, err = wal.Write(line)
+	if err == nil {
+		err = wal.Sync()
+	}
+	if err != nil {
+		// do not leave a partial record in the middle of the log
+		wal.Truncate(fj.size)
+		return err
+	}
+
+	fj.size += int64(len(line))
+	fj.records++
+	return nil
+}
+
+// checkpoint writes a snapshot once enough changes are logged. Replaying the
+// log over a newer snapshot gives the same state, so a crash between writing
+// the snapshot and truncating the log is harmless. A failed snapshot is not
+// fatal either: the log is kept and the next checkpoint tries again.
+func (fj *fileJournal) checkpoint(qs *quotesStore) {
+	if fj.records < fj.snapshotEvery {
+		return
+	}
+
+	err := fj.writeSnapshot(qs.dump())
+	if err != nil {
+		return
+	}
+
+	err = os.Truncate(fj.walPath(), 0)
+	if err != nil {
+		return
+	}
+
+	fj.size = 0
+	fj.records = 0
+}
+
+func (fj *fileJournal) writeSnapshot(c change) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := fj.snapshotPath() + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	err = os.Rename(tmpPath, fj.snapshotPath())
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.Open(fj.dir)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
@@ -0,0 +1,327 @@
+package stores
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+func sortedQuotes(t *testing.T, quotesStore QuotesStore) []types.QuoteData {
+	t.Helper()
+
+	quotes, err := quotesStore.GetAll()
+	if err != nil {
+		t.Fatalf("store returned unexpected error: %v", err)
+	}
+	slices.SortFunc(quotes, func(q1, q2 types.QuoteData) int {
+		return cmp.Compare(q1.Id, q2.Id)
+	})
+
+	return quotes
+}
+
+func TestFileStoreReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	quotesStore, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author1", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Author2", Quote: "Quote2"})
+	quotesStore.Delete(id2, nil, "")
+
+	reopened, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedQuotes := []types.QuoteData{{Id: id1, Author: "Author1", Quote: "Quote1", AuthorId: 1, CreatedAt: testTime, UpdatedAt: testTime, Version: 1}}
+	t.Run("QuotesRestored", func(t *testing.T) {
+		quotes := sortedQuotes(t, reopened)
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
+		}
+	})
+
+	expectedAuthors := []types.AuthorData{{Id: 1, Name: "Author1", QuoteCount: 1}, {Id: 2, Name: "Author2"}}
+	t.Run("AuthorsRestored", func(t *testing.T) {
+		authors, err := reopened.GetAuthors()
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(authors, expectedAuthors) {
+			t.Errorf("store returned unexpected authors: got %v want %v", authors, expectedAuthors)
+		}
+	})
+
+	t.Run("IdsNotReused", func(t *testing.T) {
+		id, err := reopened.Create(types.QuoteData{Author: "Author3", Quote: "Quote3"})
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if id != id2+1 {
+			t.Errorf("store returned unexpected id: got %v want %v", id, id2+1)
+		}
+
+		quote, _ := reopened.GetById(id)
+		if quote.AuthorId != 3 {
+			t.Errorf("store returned unexpected author id: got %v want %v", quote.AuthorId, 3)
+		}
+	})
+}
+
+func TestFileStoreLegacyLog(t *testing.T) {
+	dir := t.TempDir()
+
+	// quotes logged before authors were introduced
+	wal := `{"curr_id":1,"put":[{"id":1,"author":"Author1","quote":"Quote1"}]}
+{"curr_id":2,"put":[{"id":2,"author":"Author2","quote":"Quote2"}]}
+{"curr_id":3,"put":[{"id":3,"author":" author1 ","quote":"Quote3"}]}
+`
+	err := os.WriteFile(filepath.Join(dir, walFileName), []byte(wal), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quotesStore, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAuthors := []types.AuthorData{{Id: 1, Name: "Author1", QuoteCount: 2}, {Id: 2, Name: "Author2", QuoteCount: 1}}
+	t.Run("AuthorsLinked", func(t *testing.T) {
+		authors, err := quotesStore.GetAuthors()
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(authors, expectedAuthors) {
+			t.Errorf("store returned unexpected authors: got %v want %v", authors, expectedAuthors)
+		}
+	})
+
+	t.Run("FirstVersion", func(t *testing.T) {
+		quote, _ := quotesStore.GetById(1)
+		if quote.Version != 1 {
+			t.Errorf("store returned unexpected version: got %v want %v", quote.Version, 1)
+		}
+
+		err := quotesStore.Delete(2, types.Versions{1}, "")
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+	})
+
+	t.Run("NewAuthorIdsContinue", func(t *testing.T) {
+		id, _ := quotesStore.Create(types.QuoteData{Author: "Author3", Quote: "Quote4"})
+
+		reopened, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+		if err != nil {
+			t.Fatal(err)
+		}
+		quote, _ := reopened.GetById(id)
+		if quote.AuthorId != 3 {
+			t.Errorf("store returned unexpected author id: got %v want %v", quote.AuthorId, 3)
+		}
+	})
+}
+
+func TestFileStoreMergeReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	quotesStore, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotesStore.Create(types.QuoteData{Author: "Конфуций", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "Quote2"})
+	quotesStore.MergeAuthors(2, 1, "")
+
+	reopened, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAuthors := []types.AuthorData{{Id: 1, Name: "Конфуций", Aliases: []types.Author{"Confucius"}, QuoteCount: 2}}
+	t.Run("AuthorsRestored", func(t *testing.T) {
+		authors, _ := reopened.GetAuthors()
+		if !reflect.DeepEqual(authors, expectedAuthors) {
+			t.Errorf("store returned unexpected authors: got %v want %v", authors, expectedAuthors)
+		}
+
+		quote, _ := reopened.GetById(id2)
+		if quote.AuthorId != 1 {
+			t.Errorf("store returned unexpected author id: got %v want %v", quote.AuthorId, 1)
+		}
+	})
+}
+
+func TestFileStoreTrashReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	quotesStore, err := NewFileQuotesStore(dir, 3, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author1", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Author2", Quote: "Quote2"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Author3", Quote: "Quote3"})
+	// the snapshot is written here, so trashed quotes go through it too
+	quotesStore.Delete(id1, nil, "")
+	quotesStore.Delete(id2, nil, "")
+	quotesStore.Delete(id3, nil, "")
+	quotesStore.Restore(id2, "")
+	quotesStore.Purge(id3)
+
+	reopened, err := NewFileQuotesStore(dir, 3, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deletedAt := testTime
+	expectedTrash := []types.QuoteData{{Id: id1, Author: "Author1", Quote: "Quote1", AuthorId: 1, CreatedAt: testTime, UpdatedAt: testTime, Version: 2, DeletedAt: &deletedAt}}
+	t.Run("TrashRestored", func(t *testing.T) {
+		trash, _ := reopened.GetTrash()
+		if !reflect.DeepEqual(trash, expectedTrash) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", trash, expectedTrash)
+		}
+	})
+
+	expectedQuotes := []types.QuoteData{{Id: id2, Author: "Author2", Quote: "Quote2", AuthorId: 2, CreatedAt: testTime, UpdatedAt: testTime, Version: 3}}
+	t.Run("QuotesRestored", func(t *testing.T) {
+		quotes := sortedQuotes(t, reopened)
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
+		}
+	})
+}
+
+func TestFileStoreRevisionsReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	quotesStore, err := NewFileQuotesStore(dir, 2, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote1"})
+	for _, text := range []types.Quote{"Quote2", "Quote3"} {
+		quotesStore.Update(id, func(quote *types.QuoteData) error {
+			quote.Quote = text
+			return nil
+		})
+	}
+	expected, _ := quotesStore.GetRevisions(id)
+
+	reopened, err := NewFileQuotesStore(dir, 2, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("RevisionsRestored", func(t *testing.T) {
+		revisions, _ := reopened.GetRevisions(id)
+		if len(revisions) != 3 || !reflect.DeepEqual(revisions, expected) {
+			t.Errorf("store returned unexpected revisions: got %v want %v", revisions, expected)
+		}
+	})
+}
+
+func TestFileStoreSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	quotesStore, err := NewFileQuotesStore(dir, 2, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author1", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Author2", Quote: "Quote2"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Author3", Quote: "Quote3"})
+
+	t.Run("SnapshotWritten", func(t *testing.T) {
+		_, err := os.Stat(filepath.Join(dir, snapshotFileName))
+		if err != nil {
+			t.Errorf("store did not write snapshot: %v", err)
+		}
+
+		wal, err := os.ReadFile(filepath.Join(dir, walFileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := 0
+		for _, b := range wal {
+			if b == '\n' {
+				lines++
+			}
+		}
+		if lines != 1 {
+			t.Errorf("store kept unexpected number of log records: got %v want %v", lines, 1)
+		}
+	})
+
+	reopened, err := NewFileQuotesStore(dir, 2, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedQuotes := []types.QuoteData{
+		{Id: id1, Author: "Author1", Quote: "Quote1", AuthorId: 1, CreatedAt: testTime, UpdatedAt: testTime, Version: 1},
+		{Id: id2, Author: "Author2", Quote: "Quote2", AuthorId: 2, CreatedAt: testTime, UpdatedAt: testTime, Version: 1},
+		{Id: id3, Author: "Author3", Quote: "Quote3", AuthorId: 3, CreatedAt: testTime, UpdatedAt: testTime, Version: 1},
+	}
+	t.Run("QuotesRestored", func(t *testing.T) {
+		quotes := sortedQuotes(t, reopened)
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
+		}
+	})
+}
+
+func TestFileStoreTornWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	quotesStore, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author1", Quote: "Quote1"})
+
+	wal, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal.Write([]byte(`{"curr_id":2,"put":[{"id":2,"aut`))
+	wal.Close()
+
+	reopened, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+	if err != nil {
+		t.Fatalf("store failed to recover from torn write: %v", err)
+	}
+
+	expectedQuotes := []types.QuoteData{{Id: id1, Author: "Author1", Quote: "Quote1", AuthorId: 1, CreatedAt: testTime, UpdatedAt: testTime, Version: 1}}
+	t.Run("TornRecordDropped", func(t *testing.T) {
+		quotes := sortedQuotes(t, reopened)
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
+		}
+	})
+
+	t.Run("LogAppendable", func(t *testing.T) {
+		id, err := reopened.Create(types.QuoteData{Author: "Author2", Quote: "Quote2"})
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+
+		again, err := NewFileQuotesStore(dir, 100, WithClock(testClock))
+		if err != nil {
+			t.Fatal(err)
+		}
+		quotes := sortedQuotes(t, again)
+		if len(quotes) != 2 || quotes[1].Id != id {
+			t.Errorf("store returned unexpected quotes: %v", quotes)
+		}
+	})
+}
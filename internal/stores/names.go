@@ -0,0 +1,69 @@
+package stores
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+// nameIndex holds author names sorted for prefix lookups. Every word of a
+// name starts an entry, so "толс" finds "Лев Толстой".
+type nameIndex []nameEntry
+
+type nameEntry struct {
+	name types.Author // a suffix of Author.Key starting at a word
+	id   types.Id
+}
+
+func compareNameEntries(a, b nameEntry) int {
+	return cmp.Or(strings.Compare(string(a.name), string(b.name)), cmp.Compare(a.id, b.id))
+}
+
+// nameSuffixes returns suffixes of key starting at each of its words.
+func nameSuffixes(key types.Author) []types.Author {
+	suffixes := []types.Author{key}
+	for i, r := range key {
+		if r == ' ' {
+			suffixes = append(suffixes, key[i+1:])
+		}
+	}
+
+	return suffixes
+}
+
+func (ni *nameIndex) add(key types.Author, id types.Id) {
+	for _, name := range nameSuffixes(key) {
+		entry := nameEntry{name: name, id: id}
+		pos, found := slices.BinarySearchFunc(*ni, entry, compareNameEntries)
+		if !found {
+			*ni = slices.Insert(*ni, pos, entry)
+		}
+	}
+}
+
+func (ni *nameIndex) remove(key types.Author, id types.Id) {
+	for _, name := range nameSuffixes(key) {
+		pos, found := slices.BinarySearchFunc(*ni, nameEntry{name: name, id: id}, compareNameEntries)
+		if found {
+			*ni = slices.Delete(*ni, pos, pos+1)
+		}
+	}
+}
+
+// lookup returns ids of authors having a name word starting with prefix, which
+// should be an Author.Key. Every id is returned once.
+func (ni nameIndex) lookup(prefix types.Author) []types.Id {
+	pos, _ := slices.BinarySearchFunc(ni, nameEntry{name: prefix}, compareNameEntries)
+
+	ids := make([]types.Id, 0)
+	for _, entry := range ni[pos:] {
+		if !strings.HasPrefix(string(entry.name), string(prefix)) {
+			break
+		}
+		ids = insertId(ids, entry.id)
+	}
+
+	return ids
+}
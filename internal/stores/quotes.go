@@ -1,105 +1,710 @@
 package stores
 
 import (
-	"fmt"
+	"errors"
 	"math"
 	"math/rand"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/NikitaBogoslovskiy/quotes/internal/dedup"
+	"github.com/NikitaBogoslovskiy/quotes/internal/search"
 	"github.com/NikitaBogoslovskiy/quotes/internal/types"
 )
 
+var (
+	ErrQuoteNotFound    = errors.New("no quote with specified id")
+	ErrNoQuotes         = errors.New("no quotes to retrieve")
+	ErrCapacityExceeded = errors.New("space limit exceeded")
+	ErrVersionMismatch  = errors.New("quote has been changed since specified version")
+	ErrDuplicateQuote   = errors.New("author already has the same quote")
+)
+
+// DuplicateQuoteError is returned by Create, Update and Restore for a quote of
+// the same author and with the same text up to punctuation, whitespace and
+// case as the live quote with Id.
+type DuplicateQuoteError struct {
+	Id types.Id
+}
+
+func (e DuplicateQuoteError) Error() string {
+	return ErrDuplicateQuote.Error()
+}
+
+func (e DuplicateQuoteError) Unwrap() error {
+	return ErrDuplicateQuote
+}
+
 type QuotesStore interface {
-	Create(author types.Author, quote types.Quote) (types.Id, error)
+	// Create saves a new quote. Its id and timestamps are assigned by the store.
+	// A quote the author already has is not saved, DuplicateQuoteError is
+	// returned then.
+	Create(quote types.QuoteData) (types.Id, error)
 	GetAll() ([]types.QuoteData, error)
 	GetByAuthor(author types.Author) ([]types.QuoteData, error)
 	GetRandom() (types.QuoteData, error)
-	Delete(id types.Id) error
+	// GetTags returns all tags in use, the most popular first.
+	GetTags() ([]types.TagCount, error)
+	GetById(id types.Id) (types.QuoteData, error)
+	// List returns a page of quotes matching query in the order of query.Sort.
+	// more reports whether there are matching quotes after the page.
+	List(query types.QuotesQuery) (quotes []types.QuoteData, more bool, err error)
+	// Search returns at most limit quotes whose text matches query, the most
+	// relevant first.
+	Search(query search.Query, limit int) ([]types.SearchHit, error)
+	// Update atomically applies modify to the quote with specified id and
+	// saves the result. Nothing is saved if modify returns an error or the
+	// changed text or author duplicates another quote (see Create).
+	Update(id types.Id, modify func(quote *types.QuoteData) error) (types.QuoteData, error)
+	// Delete moves the quote to the trash if its version matches versions.
+	// Quotes in the trash are not returned by other methods until they are
+	// restored. actor is saved as the last one who changed the quote, the
+	// same as by Restore.
+	Delete(id types.Id, versions types.Versions, actor string) error
+	// GetTrash returns quotes in the trash in ascending order of ids.
+	GetTrash() ([]types.QuoteData, error)
+	// Restore moves the quote with specified id back from the trash unless
+	// the author has got the same quote since it was deleted.
+	Restore(id types.Id, actor string) (types.QuoteData, error)
+	// Purge permanently removes the quote with specified id from the trash.
+	Purge(id types.Id) error
+	// PurgeTrash permanently removes quotes deleted before deletedBefore, or
+	// all quotes in the trash if it is zero, and returns their number.
+	PurgeTrash(deletedBefore time.Time) (int, error)
+	// GetRevisions returns revisions of the quote with specified id, which may
+	// be in the trash, in ascending order of numbers.
+	GetRevisions(id types.Id) ([]types.Revision, error)
+	// GetDuplicates returns other quotes whose texts are similar to the text
+	// of the quote with specified id at least by threshold, the most similar
+	// first.
+	GetDuplicates(id types.Id, threshold float64) ([]types.Duplicate, error)
 }
 
+// quotesStore keeps quotes densely packed in a slice so that a random quote
+// can be picked in constant time. positions maps quote ids to their indexes
+// in the slice; Delete moves the last quote into the freed slot.
+// ids holds all quote ids in ascending order for listings. Deleted quotes are
+// kept apart in trash and are not indexed; trashIds holds their ids in
+// ascending order. revisions holds the history of every quote, live or
+// deleted; quotes saved before revisions were introduced have none.
+// Every quote is linked to an author. authorKeys maps keys of names and
+// aliases of authors (see Author.Key) to author ids, so differently written
+// names of an author match, and names indexes them for autocompletion.
+// byTranslit and latinNames do the same for Author.TranslitKey, which may be
+// shared by several authors. byAuthor maps author ids to their quotes.
+// byContent maps authors together with keys of texts (see Quote.Key) to
+// quotes to reject exact duplicates, while similar finds near-duplicates.
+// Reads only take mtx for reading, so they do not block each other.
 type quotesStore struct {
-	mtx    sync.Mutex
-	currId types.Id
-	data   map[types.Id]types.QuoteData
+	mtx          sync.RWMutex
+	currId       types.Id
+	quotes       []types.QuoteData
+	positions    map[types.Id]int
+	ids          []types.Id
+	trash        map[types.Id]types.QuoteData
+	trashIds     []types.Id
+	revisions    map[types.Id][]types.Revision
+	currAuthorId types.Id
+	authors      map[types.Id]types.AuthorData
+	authorIds    []types.Id
+	authorKeys   map[types.Author]types.Id
+	names        nameIndex
+	byTranslit   idIndex[types.Author]
+	latinNames   nameIndex
+	byAuthor     idIndex[types.Id]
+	byTag        idIndex[types.Tag]
+	text         *search.Index
+	byContent    idIndex[contentKey]
+	similar      *dedup.Index
+	journal      journal
+	clock        func() time.Time
+}
+
+type Option func(qs *quotesStore)
+
+// WithClock makes the store take creation and modification times from clock.
+func WithClock(clock func() time.Time) Option {
+	return func(qs *quotesStore) {
+		qs.clock = clock
+	}
 }
 
-func NewQuotesStore() QuotesStore {
-	return &quotesStore{data: make(map[types.Id]types.QuoteData)}
+// change is a single mutation of the store. Authors with ids in DeleteAuthors
+// are removed first, then authors in PutAuthors and quotes in Put are stored
+// as is (replacing existing ones with the same id) and quotes with ids in
+// Delete are removed permanently along with their revisions. Quotes with
+// DeletedAt set are put into the trash. Revisions are added to the history of
+// their quotes. Every mutation goes through commit, so a journal sees
+// exactly what is applied.
+type change struct {
+	CurrId        types.Id           `json:"curr_id"`
+	CurrAuthorId  types.Id           `json:"curr_author_id,omitempty"`
+	DeleteAuthors []types.Id         `json:"delete_authors,omitempty"`
+	PutAuthors    []types.AuthorData `json:"put_authors,omitempty"`
+	Put           []types.QuoteData  `json:"put,omitempty"`
+	Delete        []types.Id         `json:"delete,omitempty"`
+	Revisions     []types.Revision   `json:"revisions,omitempty"`
 }
 
-func (qs *quotesStore) Create(author types.Author, quote types.Quote) (types.Id, error) {
+// journal persists changes before they are applied to the store.
+type journal interface {
+	record(c change) error
+	checkpoint(qs *quotesStore)
+}
+
+func NewQuotesStore(options ...Option) Store {
+	return newQuotesStore(options...)
+}
+
+func newQuotesStore(options ...Option) *quotesStore {
+	qs := &quotesStore{
+		positions:  make(map[types.Id]int),
+		trash:      make(map[types.Id]types.QuoteData),
+		revisions:  make(map[types.Id][]types.Revision),
+		authors:    make(map[types.Id]types.AuthorData),
+		authorKeys: make(map[types.Author]types.Id),
+		byTranslit: make(idIndex[types.Author]),
+		byAuthor:   make(idIndex[types.Id]),
+		byTag:      make(idIndex[types.Tag]),
+		text:       search.NewIndex(),
+		byContent:  make(idIndex[contentKey]),
+		similar:    dedup.NewIndex(),
+		clock:      time.Now,
+	}
+
+	for _, option := range options {
+		option(qs)
+	}
+
+	return qs
+}
+
+// now strips the monotonic clock reading, so timestamps survive persistence as is.
+func (qs *quotesStore) now() time.Time {
+	return qs.clock().UTC()
+}
+
+func (qs *quotesStore) Create(quote types.QuoteData) (types.Id, error) {
 	qs.mtx.Lock()
 	defer qs.mtx.Unlock()
 
 	if qs.currId == math.MaxUint64 {
-		return 0, fmt.Errorf("space limit exceeded")
+		return 0, ErrCapacityExceeded
+	}
+	id := qs.currId + 1
+	now := qs.now()
+
+	quote.Id = id
+	quote.CreatedAt = now
+	quote.UpdatedAt = now
+	quote.Version = 1
+
+	c := change{CurrId: id, CurrAuthorId: qs.currAuthorId}
+	qs.linkAuthor(&quote, &c)
+	duplicate, ok := qs.duplicateOf(quote)
+	if ok {
+		return 0, DuplicateQuoteError{Id: duplicate}
 	}
-	qs.currId++
+	c.Put = []types.QuoteData{quote}
+	c.Revisions = []types.Revision{types.NewRevision(quote, 1)}
 
-	qs.data[qs.currId] = types.QuoteData{
-		Id:     qs.currId,
-		Author: author,
-		Quote:  quote,
+	err := qs.commit(c)
+	if err != nil {
+		return 0, err
 	}
 
-	return qs.currId, nil
+	return id, nil
 }
 
 func (qs *quotesStore) GetAll() ([]types.QuoteData, error) {
-	qs.mtx.Lock()
-	defer qs.mtx.Unlock()
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
 
-	quotes := make([]types.QuoteData, 0, len(qs.data))
-	for _, quote := range qs.data {
-		quotes = append(quotes, quote)
+	quotes := make([]types.QuoteData, 0, len(qs.ids))
+	for _, id := range qs.ids {
+		quotes = append(quotes, qs.quotes[qs.positions[id]])
 	}
 
 	return quotes, nil
 }
 
 func (qs *quotesStore) GetByAuthor(author types.Author) ([]types.QuoteData, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	ids := qs.byAuthor[qs.authorKeys[author.Key()]]
+	quotes := make([]types.QuoteData, 0, len(ids))
+	for _, id := range ids {
+		quotes = append(quotes, qs.quotes[qs.positions[id]])
+	}
+
+	return quotes, nil
+}
+
+func (qs *quotesStore) GetRandom() (types.QuoteData, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	quotesNumber := len(qs.quotes)
+	if quotesNumber == 0 {
+		return types.QuoteData{}, ErrNoQuotes
+	}
+
+	return qs.quotes[rand.Intn(quotesNumber)], nil
+}
+
+func (qs *quotesStore) GetTags() ([]types.TagCount, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	tags := make([]types.TagCount, 0, len(qs.byTag))
+	for tag, ids := range qs.byTag {
+		tags = append(tags, types.TagCount{Tag: tag, Count: len(ids)})
+	}
+	slices.SortFunc(tags, func(a, b types.TagCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(string(a.Tag), string(b.Tag))
+	})
+
+	return tags, nil
+}
+
+func (qs *quotesStore) GetById(id types.Id) (types.QuoteData, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	pos, ok := qs.positions[id]
+	if !ok {
+		return types.QuoteData{}, ErrQuoteNotFound
+	}
+
+	return qs.quotes[pos], nil
+}
+
+func (qs *quotesStore) List(query types.QuotesQuery) ([]types.QuoteData, bool, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	ids := qs.ids
+	if query.Author != "" {
+		ids = qs.quotesOf(query.Author, query.AuthorMatch)
+	}
+	if query.AuthorId != 0 {
+		ids = intersectIds(ids, qs.byAuthor[query.AuthorId])
+	}
+	if len(query.Tags) != 0 {
+		ids = intersectIds(ids, qs.taggedIds(query.Tags, query.AnyTag))
+	}
+
+	if query.Sort.Field == types.SortById || query.Sort.Field == "" {
+		quotes, more := qs.listByIds(ids, query)
+		return quotes, more, nil
+	}
+
+	quotes, more := qs.listSorted(ids, query)
+	return quotes, more, nil
+}
+
+// quotesOf returns ids of quotes of authors matching author.
+func (qs *quotesStore) quotesOf(author types.Author, match types.AuthorMatch) []types.Id {
+	ids := qs.byAuthor[qs.authorKeys[author.Key()]]
+	if match != types.AuthorMatchTranslit {
+		return ids
+	}
+
+	for _, authorId := range qs.byTranslit[author.TranslitKey()] {
+		ids = unionIds(ids, qs.byAuthor[authorId])
+	}
+
+	return ids
+}
+
+// taggedIds returns ids of quotes having all (or any) of tags.
+func (qs *quotesStore) taggedIds(tags []types.Tag, anyTag bool) []types.Id {
+	ids := qs.byTag[tags[0]]
+	for _, tag := range tags[1:] {
+		if anyTag {
+			ids = unionIds(ids, qs.byTag[tag])
+		} else {
+			ids = intersectIds(ids, qs.byTag[tag])
+		}
+	}
+
+	return ids
+}
+
+// listByIds pages through ids sorted in ascending order without sorting quotes.
+func (qs *quotesStore) listByIds(ids []types.Id, query types.QuotesQuery) ([]types.QuoteData, bool) {
+	start, end, step := 0, len(ids), 1
+	if query.Sort.Desc {
+		start, end, step = len(ids)-1, -1, -1
+	}
+
+	if query.After != nil {
+		pos, found := slices.BinarySearch(ids, query.After.Id)
+		if query.Sort.Desc {
+			start = pos - 1
+		} else if found {
+			start = pos + 1
+		} else {
+			start = pos
+		}
+	}
+
+	quotes := make([]types.QuoteData, 0)
+	for i := start; i != end; i += step {
+		quote := qs.quotes[qs.positions[ids[i]]]
+		if !query.Matches(quote) {
+			continue
+		}
+		if query.Limit > 0 && len(quotes) == query.Limit {
+			return quotes, true
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, false
+}
+
+// listSorted sorts matching quotes with specified ids and pages through them.
+func (qs *quotesStore) listSorted(ids []types.Id, query types.QuotesQuery) ([]types.QuoteData, bool) {
+	keys := make([]types.Cursor, 0, len(ids))
+	for _, id := range ids {
+		quote := qs.quotes[qs.positions[id]]
+		if query.Matches(quote) {
+			keys = append(keys, types.NewCursor(query.Sort, quote))
+		}
+	}
+	slices.SortFunc(keys, query.Sort.Compare)
+
+	start := 0
+	if query.After != nil {
+		pos, found := slices.BinarySearchFunc(keys, *query.After, query.Sort.Compare)
+		if found {
+			pos++
+		}
+		start = pos
+	}
+
+	end := len(keys)
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+	}
+
+	quotes := make([]types.QuoteData, 0, end-start)
+	for _, key := range keys[start:end] {
+		quotes = append(quotes, qs.quotes[qs.positions[key.Id]])
+	}
+
+	return quotes, end < len(keys)
+}
+
+func (qs *quotesStore) Search(query search.Query, limit int) ([]types.SearchHit, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	hits := qs.text.Search(query, limit)
+	results := make([]types.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		quote := qs.quotes[qs.positions[hit.Id]]
+		results = append(results, types.SearchHit{
+			Quote:   quote,
+			Score:   hit.Score,
+			Snippet: search.Snippet(string(quote.Quote), hit.Terms),
+		})
+	}
+
+	return results, nil
+}
+
+func (qs *quotesStore) Update(id types.Id, modify func(quote *types.QuoteData) error) (types.QuoteData, error) {
 	qs.mtx.Lock()
 	defer qs.mtx.Unlock()
 
-	quotes := make([]types.QuoteData, 0)
-	for _, quote := range qs.data {
-		if quote.Author == author {
-			quotes = append(quotes, quote)
+	pos, ok := qs.positions[id]
+	if !ok {
+		return types.QuoteData{}, ErrQuoteNotFound
+	}
+
+	quote := qs.quotes[pos]
+	err := modify(&quote)
+	if err != nil {
+		return types.QuoteData{}, err
+	}
+	old := qs.quotes[pos]
+	quote.Id = id
+	quote.AuthorId = old.AuthorId
+	quote.CreatedAt = old.CreatedAt
+	quote.UpdatedAt = qs.now()
+	quote.Version = old.Version + 1
+
+	c := change{CurrId: qs.currId, CurrAuthorId: qs.currAuthorId}
+	// the quote stays with its author while the name matches, even if the
+	// author has been renamed since
+	if quote.Author.Key() != old.Author.Key() {
+		qs.linkAuthor(&quote, &c)
+	}
+	// quotes duplicated by merging authors can still be changed otherwise
+	if contentKeyOf(quote) != contentKeyOf(old) {
+		duplicate, ok := qs.duplicateOf(quote)
+		if ok {
+			return types.QuoteData{}, DuplicateQuoteError{Id: duplicate}
 		}
 	}
+	c.Put = []types.QuoteData{quote}
+	c.Revisions = qs.revise(old, quote)
+
+	err = qs.commit(c)
+	if err != nil {
+		return types.QuoteData{}, err
+	}
+
+	return quote, nil
+}
+
+func (qs *quotesStore) Delete(id types.Id, versions types.Versions, actor string) error {
+	qs.mtx.Lock()
+	defer qs.mtx.Unlock()
+
+	pos, ok := qs.positions[id]
+	if !ok {
+		return ErrQuoteNotFound
+	}
+
+	quote := qs.quotes[pos]
+	if !versions.Match(quote.Version) {
+		return ErrVersionMismatch
+	}
+	now := qs.now()
+	quote.DeletedAt = &now
+	quote.UpdatedAt = now
+	quote.UpdatedBy = actor
+	quote.Version++
+
+	return qs.commit(change{CurrId: qs.currId, CurrAuthorId: qs.currAuthorId, Put: []types.QuoteData{quote}})
+}
+
+func (qs *quotesStore) GetTrash() ([]types.QuoteData, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	quotes := make([]types.QuoteData, 0, len(qs.trashIds))
+	for _, id := range qs.trashIds {
+		quotes = append(quotes, qs.trash[id])
+	}
 
 	return quotes, nil
 }
 
-func (qs *quotesStore) GetRandom() (types.QuoteData, error) {
+func (qs *quotesStore) Restore(id types.Id, actor string) (types.QuoteData, error) {
 	qs.mtx.Lock()
 	defer qs.mtx.Unlock()
 
-	quotesNumber := len(qs.data)
-	if quotesNumber == 0 {
-		return types.QuoteData{}, fmt.Errorf("no quotes to retrieve")
+	quote, ok := qs.trash[id]
+	if !ok {
+		return types.QuoteData{}, ErrQuoteNotFound
 	}
+	duplicate, ok := qs.duplicateOf(quote)
+	if ok {
+		return types.QuoteData{}, DuplicateQuoteError{Id: duplicate}
+	}
+	quote.DeletedAt = nil
+	quote.UpdatedAt = qs.now()
+	quote.UpdatedBy = actor
+	quote.Version++
 
-	randomIdx := rand.Intn(quotesNumber)
-	for _, quote := range qs.data {
-		if randomIdx == 0 {
-			return quote, nil
-		}
-		randomIdx--
+	err := qs.commit(change{CurrId: qs.currId, CurrAuthorId: qs.currAuthorId, Put: []types.QuoteData{quote}})
+	if err != nil {
+		return types.QuoteData{}, err
 	}
 
-	return types.QuoteData{}, fmt.Errorf("internal server error")
+	return quote, nil
 }
 
-func (qs *quotesStore) Delete(id types.Id) error {
+func (qs *quotesStore) Purge(id types.Id) error {
 	qs.mtx.Lock()
 	defer qs.mtx.Unlock()
 
-	_, ok := qs.data[id]
+	_, ok := qs.trash[id]
 	if !ok {
-		return fmt.Errorf("no quote with specified id")
+		return ErrQuoteNotFound
+	}
+
+	return qs.commit(change{CurrId: qs.currId, CurrAuthorId: qs.currAuthorId, Delete: []types.Id{id}})
+}
+
+func (qs *quotesStore) PurgeTrash(deletedBefore time.Time) (int, error) {
+	qs.mtx.Lock()
+	defer qs.mtx.Unlock()
+
+	ids := make([]types.Id, 0)
+	for _, id := range qs.trashIds {
+		if deletedBefore.IsZero() || qs.trash[id].DeletedAt.Before(deletedBefore) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err := qs.commit(change{CurrId: qs.currId, CurrAuthorId: qs.currAuthorId, Delete: ids})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// commit must be called with qs.mtx held.
+func (qs *quotesStore) commit(c change) error {
+	if qs.journal != nil {
+		err := qs.journal.record(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	qs.apply(c)
+
+	if qs.journal != nil {
+		qs.journal.checkpoint(qs)
 	}
 
-	delete(qs.data, id)
 	return nil
 }
+
+func (qs *quotesStore) apply(c change) {
+	if c.CurrId > qs.currId {
+		qs.currId = c.CurrId
+	}
+	if c.CurrAuthorId > qs.currAuthorId {
+		qs.currAuthorId = c.CurrAuthorId
+	}
+
+	for _, id := range c.DeleteAuthors {
+		qs.removeAuthor(id)
+	}
+
+	for _, author := range c.PutAuthors {
+		qs.putAuthor(author)
+	}
+
+	for _, quote := range c.Put {
+		if quote.AuthorId == 0 {
+			// quotes saved before authors were introduced are linked on
+			// replay, which creates the same authors every time
+			linked := change{}
+			qs.linkAuthor(&quote, &linked)
+			qs.apply(linked)
+		}
+		if quote.Version == 0 {
+			// quotes saved before versions were introduced start with the
+			// first version, so their entity tags match in If-Match
+			quote.Version = 1
+		}
+		qs.put(quote)
+	}
+
+	for _, revision := range c.Revisions {
+		qs.putRevision(revision)
+	}
+
+	for _, id := range c.Delete {
+		qs.remove(id)
+		delete(qs.revisions, id)
+	}
+}
+
+func (qs *quotesStore) put(quote types.QuoteData) {
+	if quote.DeletedAt != nil {
+		qs.remove(quote.Id)
+		qs.trash[quote.Id] = quote
+		qs.trashIds = insertId(qs.trashIds, quote.Id)
+		return
+	}
+	if _, ok := qs.trash[quote.Id]; ok {
+		qs.remove(quote.Id)
+	}
+
+	pos, ok := qs.positions[quote.Id]
+	if ok {
+		qs.unindex(qs.quotes[pos])
+		qs.quotes[pos] = quote
+	} else {
+		qs.positions[quote.Id] = len(qs.quotes)
+		qs.quotes = append(qs.quotes, quote)
+		qs.ids = insertId(qs.ids, quote.Id)
+	}
+
+	qs.index(quote)
+}
+
+// remove removes the quote with specified id from the store or the trash.
+func (qs *quotesStore) remove(id types.Id) {
+	if _, ok := qs.trash[id]; ok {
+		delete(qs.trash, id)
+		qs.trashIds = removeId(qs.trashIds, id)
+		return
+	}
+
+	pos, ok := qs.positions[id]
+	if !ok {
+		return
+	}
+
+	qs.unindex(qs.quotes[pos])
+
+	last := len(qs.quotes) - 1
+	if pos != last {
+		qs.quotes[pos] = qs.quotes[last]
+		qs.positions[qs.quotes[pos].Id] = pos
+	}
+	qs.quotes[last] = types.QuoteData{}
+	qs.quotes = qs.quotes[:last]
+	delete(qs.positions, id)
+	qs.ids = removeId(qs.ids, id)
+}
+
+func (qs *quotesStore) index(quote types.QuoteData) {
+	qs.byAuthor.add(quote.AuthorId, quote.Id)
+	for _, tag := range quote.Tags {
+		qs.byTag.add(tag, quote.Id)
+	}
+	qs.text.Add(quote.Id, string(quote.Quote))
+	qs.byContent.add(contentKeyOf(quote), quote.Id)
+	qs.similar.Add(quote.Id, quote.Quote)
+}
+
+func (qs *quotesStore) unindex(quote types.QuoteData) {
+	qs.byAuthor.remove(quote.AuthorId, quote.Id)
+	for _, tag := range quote.Tags {
+		qs.byTag.remove(tag, quote.Id)
+	}
+	qs.text.Remove(quote.Id)
+	qs.byContent.remove(contentKeyOf(quote), quote.Id)
+	qs.similar.Remove(quote.Id)
+}
+
+// dump returns the whole state of the store as a single change.
+func (qs *quotesStore) dump() change {
+	authors := make([]types.AuthorData, 0, len(qs.authorIds))
+	for _, id := range qs.authorIds {
+		authors = append(authors, qs.authors[id])
+	}
+
+	quotes := slices.Clone(qs.quotes)
+	for _, id := range qs.trashIds {
+		quotes = append(quotes, qs.trash[id])
+	}
+
+	revisions := make([]types.Revision, 0)
+	for _, quote := range quotes {
+		revisions = append(revisions, qs.revisions[quote.Id]...)
+	}
+
+	return change{CurrId: qs.currId, CurrAuthorId: qs.currAuthorId, PutAuthors: authors, Put: quotes, Revisions: revisions}
+}
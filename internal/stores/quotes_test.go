@@ -1,15 +1,27 @@
 package stores
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
+	"reflect"
 	"slices"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/NikitaBogoslovskiy/quotes/internal/search"
 	"github.com/NikitaBogoslovskiy/quotes/internal/types"
 )
 
+var testTime = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func testClock() time.Time {
+	return testTime
+}
+
 func TestCreate(t *testing.T) {
-	quotesStore := &quotesStore{data: make(map[types.Id]types.QuoteData)}
+	quotesStore := newQuotesStore(WithClock(testClock))
 
 	type input struct {
 		Author types.Author
@@ -40,7 +52,7 @@ func TestCreate(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			id, err := quotesStore.Create(tc.input.Author, tc.input.Quote)
+			id, err := quotesStore.Create(types.QuoteData{Author: tc.input.Author, Quote: tc.input.Quote})
 			if err != tc.expected.Err {
 				t.Errorf("store returned unexpected error: got %v want %v", err, tc.expected.Err)
 			}
@@ -49,10 +61,11 @@ func TestCreate(t *testing.T) {
 			}
 
 			if err == nil {
-				record, ok := quotesStore.data[id]
+				pos, ok := quotesStore.positions[id]
 				if !ok {
 					t.Errorf("store did not save record: no quote with id = %v", id)
 				} else {
+					record := quotesStore.quotes[pos]
 					if record.Author != tc.input.Author {
 						t.Errorf("store saved record with wrong author: got %v want %v", record.Author, tc.input.Author)
 					}
@@ -66,7 +79,7 @@ func TestCreate(t *testing.T) {
 }
 
 func TestGetAll(t *testing.T) {
-	quotesStore := &quotesStore{data: make(map[types.Id]types.QuoteData)}
+	quotesStore := newQuotesStore(WithClock(testClock))
 
 	expectedQuotes := make([]types.QuoteData, 0)
 	t.Run("NoQuotes", func(t *testing.T) {
@@ -74,7 +87,7 @@ func TestGetAll(t *testing.T) {
 		if err != nil {
 			t.Errorf("store returned unexpected error: %v", err)
 		}
-		if !slices.Equal(quotes, expectedQuotes) {
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
 			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
 		}
 	})
@@ -85,18 +98,26 @@ func TestGetAll(t *testing.T) {
 		author2 types.Author = "Author2"
 		quote2  types.Quote  = "Quote2"
 	)
-	id1, _ := quotesStore.Create(author1, quote1)
-	id2, _ := quotesStore.Create(author2, quote2)
+	id1, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: quote1})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: author2, Quote: quote2})
 	expectedQuotes = []types.QuoteData{
 		{
-			Id:     id1,
-			Author: author1,
-			Quote:  quote1,
+			Id:        id1,
+			Author:    author1,
+			Quote:     quote1,
+			AuthorId:  1,
+			CreatedAt: testTime,
+			UpdatedAt: testTime,
+			Version:   1,
 		},
 		{
-			Id:     id2,
-			Author: author2,
-			Quote:  quote2,
+			Id:        id2,
+			Author:    author2,
+			Quote:     quote2,
+			AuthorId:  2,
+			CreatedAt: testTime,
+			UpdatedAt: testTime,
+			Version:   1,
 		},
 	}
 	t.Run("TwoQuotes", func(t *testing.T) {
@@ -104,17 +125,14 @@ func TestGetAll(t *testing.T) {
 		if err != nil {
 			t.Errorf("store returned unexpected error: %v", err)
 		}
-		slices.SortFunc(quotes, func(q1, q2 types.QuoteData) int {
-			return int(q1.Id - q2.Id)
-		})
-		if !slices.Equal(quotes, expectedQuotes) {
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
 			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
 		}
 	})
 }
 
 func TestGetByAuthor(t *testing.T) {
-	quotesStore := &quotesStore{data: make(map[types.Id]types.QuoteData)}
+	quotesStore := newQuotesStore(WithClock(testClock))
 
 	var (
 		author1 types.Author = "Author1"
@@ -123,9 +141,9 @@ func TestGetByAuthor(t *testing.T) {
 		quote2  types.Quote  = "Quote2"
 		quote3  types.Quote  = "Quote3"
 	)
-	id1, _ := quotesStore.Create(author1, quote1)
-	quotesStore.Create(author2, quote2)
-	id3, _ := quotesStore.Create(author1, quote3)
+	id1, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: quote1})
+	quotesStore.Create(types.QuoteData{Author: author2, Quote: quote2})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: quote3})
 
 	expectedQuotes := make([]types.QuoteData, 0)
 	t.Run("WrongAuthor", func(t *testing.T) {
@@ -133,21 +151,29 @@ func TestGetByAuthor(t *testing.T) {
 		if err != nil {
 			t.Errorf("store returned unexpected error: %v", err)
 		}
-		if !slices.Equal(quotes, expectedQuotes) {
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
 			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
 		}
 	})
 
 	expectedQuotes = []types.QuoteData{
 		{
-			Id:     id1,
-			Author: author1,
-			Quote:  quote1,
+			Id:        id1,
+			Author:    author1,
+			Quote:     quote1,
+			AuthorId:  1,
+			CreatedAt: testTime,
+			UpdatedAt: testTime,
+			Version:   1,
 		},
 		{
-			Id:     id3,
-			Author: author1,
-			Quote:  quote3,
+			Id:        id3,
+			Author:    author1,
+			Quote:     quote3,
+			AuthorId:  1,
+			CreatedAt: testTime,
+			UpdatedAt: testTime,
+			Version:   1,
 		},
 	}
 	t.Run("CorrectAuthor", func(t *testing.T) {
@@ -155,17 +181,107 @@ func TestGetByAuthor(t *testing.T) {
 		if err != nil {
 			t.Errorf("store returned unexpected error: %v", err)
 		}
-		slices.SortFunc(quotes, func(q1, q2 types.QuoteData) int {
-			return int(q1.Id - q2.Id)
-		})
-		if !slices.Equal(quotes, expectedQuotes) {
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
+		}
+	})
+
+	quotesStore.Delete(id1, nil, "")
+	id4, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: quote1})
+	expectedQuotes = []types.QuoteData{
+		{
+			Id:        id3,
+			Author:    author1,
+			Quote:     quote3,
+			AuthorId:  1,
+			CreatedAt: testTime,
+			UpdatedAt: testTime,
+			Version:   1,
+		},
+		{
+			Id:        id4,
+			Author:    author1,
+			Quote:     quote1,
+			AuthorId:  1,
+			CreatedAt: testTime,
+			UpdatedAt: testTime,
+			Version:   1,
+		},
+	}
+	t.Run("AfterDelete", func(t *testing.T) {
+		quotes, err := quotesStore.GetByAuthor(author1)
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(quotes, expectedQuotes) {
 			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedQuotes)
 		}
 	})
 }
 
+func TestGetByAuthorNormalized(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: " CONFUCIUS ", Quote: "Quote2"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Фёдор  Достоевский", Quote: "Quote3"})
+	quotesStore.Create(types.QuoteData{Author: "Confucius Jr", Quote: "Quote4"})
+
+	testCases := []struct {
+		name     string
+		input    types.Author
+		expected []types.Id
+	}{
+		{
+			name:     "LowerCase",
+			input:    "confucius",
+			expected: []types.Id{id1, id2},
+		},
+		{
+			name:     "Spaces",
+			input:    "\tConfucius  ",
+			expected: []types.Id{id1, id2},
+		},
+		{
+			name:     "YoFolded",
+			input:    "федор достоевский",
+			expected: []types.Id{id3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotes, err := quotesStore.GetByAuthor(tc.input)
+			if err != nil {
+				t.Errorf("store returned unexpected error: %v", err)
+			}
+			got := make([]types.Id, 0, len(quotes))
+			for _, quote := range quotes {
+				got = append(got, quote.Id)
+			}
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("store returned unexpected quotes: got %v want %v", got, tc.expected)
+			}
+		})
+	}
+
+	t.Run("DisplayNameKept", func(t *testing.T) {
+		quote, _ := quotesStore.GetById(id2)
+		if quote.Author != " CONFUCIUS " {
+			t.Errorf("store returned unexpected author: got %q want %q", quote.Author, " CONFUCIUS ")
+		}
+	})
+
+	t.Run("ListFiltered", func(t *testing.T) {
+		quotes, _, _ := quotesStore.List(types.QuotesQuery{Author: "ФЁДОР ДОСТОЕВСКИЙ"})
+		if len(quotes) != 1 || quotes[0].Id != id3 {
+			t.Errorf("store returned unexpected quotes: %v", quotes)
+		}
+	})
+}
+
 func TestGetRandom(t *testing.T) {
-	quotesStore := &quotesStore{data: make(map[types.Id]types.QuoteData)}
+	quotesStore := newQuotesStore(WithClock(testClock))
 
 	expectedError := fmt.Errorf("no quotes to retrieve")
 	t.Run("EmptyStore", func(t *testing.T) {
@@ -181,8 +297,8 @@ func TestGetRandom(t *testing.T) {
 		author2 types.Author = "Author2"
 		quote2  types.Quote  = "Quote2"
 	)
-	id1, _ := quotesStore.Create(author1, quote1)
-	id2, _ := quotesStore.Create(author2, quote2)
+	id1, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: quote1})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: author2, Quote: quote2})
 	expectedQuoteIds := map[types.Id]bool{id1: true, id2: true}
 	t.Run("StoreWithTwoQuotes", func(t *testing.T) {
 		quote, err := quotesStore.GetRandom()
@@ -195,14 +311,13 @@ func TestGetRandom(t *testing.T) {
 	})
 }
 
-func TestDelete(t *testing.T) {
-	quotesStore := &quotesStore{data: make(map[types.Id]types.QuoteData)}
+func TestGetById(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
 
-	expectedError := fmt.Errorf("no quote with specified id")
 	t.Run("EmptyStore", func(t *testing.T) {
-		err := quotesStore.Delete(types.Id(1))
-		if err == nil || err.Error() != expectedError.Error() {
-			t.Errorf("store returned unexpected error: got %v want %v", err, expectedError)
+		_, err := quotesStore.GetById(types.Id(1))
+		if err != ErrQuoteNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrQuoteNotFound)
 		}
 	})
 
@@ -212,25 +327,1323 @@ func TestDelete(t *testing.T) {
 		author2 types.Author = "Author2"
 		quote2  types.Quote  = "Quote2"
 	)
-	id1, _ := quotesStore.Create(author1, quote1)
-	quotesStore.Create(author2, quote2)
+	quotesStore.Create(types.QuoteData{Author: author1, Quote: quote1})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: author2, Quote: quote2})
 
 	t.Run("WrongId", func(t *testing.T) {
-		err := quotesStore.Delete(types.Id(50))
-		if err == nil || err.Error() != expectedError.Error() {
-			t.Errorf("store returned unexpected error: got %v want %v", err, expectedError)
+		_, err := quotesStore.GetById(types.Id(50))
+		if err != ErrQuoteNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrQuoteNotFound)
 		}
 	})
 
+	expectedQuote := types.QuoteData{Id: id2, Author: author2, Quote: quote2, AuthorId: 2, CreatedAt: testTime, UpdatedAt: testTime, Version: 1}
 	t.Run("CorrectId", func(t *testing.T) {
-		err := quotesStore.Delete(id1)
+		quote, err := quotesStore.GetById(id2)
 		if err != nil {
 			t.Errorf("store returned unexpected error: %v", err)
 		}
+		if !reflect.DeepEqual(quote, expectedQuote) {
+			t.Errorf("store returned unexpected quote: got %v want %v", quote, expectedQuote)
+		}
+	})
+}
 
-		_, ok := quotesStore.data[id1]
-		if ok {
-			t.Errorf("store did not delete quote with id = %v", id1)
+func TestList(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	var (
+		author1 types.Author = "Author1"
+		author2 types.Author = "Author2"
+	)
+	ids := make([]types.Id, 0, 6)
+	for i := 0; i < 6; i++ {
+		author := author1
+		if i%2 == 1 {
+			author = author2
+		}
+		id, _ := quotesStore.Create(types.QuoteData{Author: author, Quote: types.Quote(fmt.Sprintf("Quote%d", i))})
+		ids = append(ids, id)
+	}
+
+	quoteIds := func(quotes []types.QuoteData) []types.Id {
+		result := make([]types.Id, 0, len(quotes))
+		for _, quote := range quotes {
+			result = append(result, quote.Id)
+		}
+		return result
+	}
+
+	type output struct {
+		Ids  []types.Id
+		More bool
+	}
+
+	testCases := []struct {
+		name     string
+		input    types.QuotesQuery
+		expected output
+	}{
+		{
+			name:     "NoLimit",
+			input:    types.QuotesQuery{},
+			expected: output{Ids: ids, More: false},
+		},
+		{
+			name:     "FirstPage",
+			input:    types.QuotesQuery{Limit: 4},
+			expected: output{Ids: ids[:4], More: true},
+		},
+		{
+			name:     "LastPage",
+			input:    types.QuotesQuery{After: &types.Cursor{Id: ids[3]}, Limit: 4},
+			expected: output{Ids: ids[4:], More: false},
+		},
+		{
+			name:     "ExactPage",
+			input:    types.QuotesQuery{After: &types.Cursor{Id: ids[1]}, Limit: 4},
+			expected: output{Ids: ids[2:], More: false},
+		},
+		{
+			name:     "AuthorPage",
+			input:    types.QuotesQuery{Author: author2, Limit: 2},
+			expected: output{Ids: []types.Id{ids[1], ids[3]}, More: true},
+		},
+		{
+			name:     "UnknownAuthor",
+			input:    types.QuotesQuery{Author: "abcd", Limit: 2},
+			expected: output{Ids: []types.Id{}, More: false},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotes, more, err := quotesStore.List(tc.input)
+			if err != nil {
+				t.Errorf("store returned unexpected error: %v", err)
+			}
+			if got := quoteIds(quotes); !slices.Equal(got, tc.expected.Ids) {
+				t.Errorf("store returned unexpected quotes: got %v want %v", got, tc.expected.Ids)
+			}
+			if more != tc.expected.More {
+				t.Errorf("store returned unexpected more flag: got %v want %v", more, tc.expected.More)
+			}
+		})
+	}
+
+	t.Run("CursorSurvivesChanges", func(t *testing.T) {
+		quotes, _, _ := quotesStore.List(types.QuotesQuery{Limit: 2})
+		cursor := types.Cursor{Id: quotes[len(quotes)-1].Id}
+
+		quotesStore.Delete(ids[1], nil, "")
+		quotesStore.Delete(ids[2], nil, "")
+		newId, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: "NewQuote"})
+
+		quotes, more, _ := quotesStore.List(types.QuotesQuery{After: &cursor, Limit: 10})
+		expectedIds := []types.Id{ids[3], ids[4], ids[5], newId}
+		if got := quoteIds(quotes); !slices.Equal(got, expectedIds) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", got, expectedIds)
+		}
+		if more {
+			t.Errorf("store returned unexpected more flag: got %v want %v", more, false)
+		}
+	})
+}
+
+func TestListSorted(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "B", Quote: "Quote number one"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "A", Quote: "Short"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "C", Quote: "Цитата"})
+	id4, _ := quotesStore.Create(types.QuoteData{Author: "A", Quote: "Longer one"})
+
+	testCases := []struct {
+		name     string
+		input    types.Sort
+		expected []types.Id
+	}{
+		{
+			name:     "IdDesc",
+			input:    types.Sort{Field: types.SortById, Desc: true},
+			expected: []types.Id{id4, id3, id2, id1},
+		},
+		{
+			name:     "CreatedAsc",
+			input:    types.Sort{Field: types.SortByCreated},
+			expected: []types.Id{id1, id2, id3, id4},
+		},
+		{
+			name:     "AuthorAsc",
+			input:    types.Sort{Field: types.SortByAuthor},
+			expected: []types.Id{id2, id4, id1, id3},
+		},
+		{
+			name:     "AuthorDesc",
+			input:    types.Sort{Field: types.SortByAuthor, Desc: true},
+			expected: []types.Id{id3, id1, id4, id2},
+		},
+		{
+			name:     "LengthAsc",
+			input:    types.Sort{Field: types.SortByLength},
+			expected: []types.Id{id2, id3, id4, id1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := make([]types.Id, 0, len(tc.expected))
+			var after *types.Cursor
+			for {
+				quotes, more, err := quotesStore.List(types.QuotesQuery{Sort: tc.input, After: after, Limit: 3})
+				if err != nil {
+					t.Fatalf("store returned unexpected error: %v", err)
+				}
+				for _, quote := range quotes {
+					got = append(got, quote.Id)
+				}
+				if !more {
+					break
+				}
+				cursor := types.NewCursor(tc.input, quotes[len(quotes)-1])
+				after = &cursor
+			}
+
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("store returned unexpected order: got %v want %v", got, tc.expected)
+			}
+		})
+	}
+
+	t.Run("CursorSurvivesDeletion", func(t *testing.T) {
+		sort := types.Sort{Field: types.SortByAuthor}
+		quotes, _, _ := quotesStore.List(types.QuotesQuery{Sort: sort, Limit: 2})
+		cursor := types.NewCursor(sort, quotes[len(quotes)-1])
+		quotesStore.Delete(id4, nil, "")
+
+		quotes, _, _ = quotesStore.List(types.QuotesQuery{Sort: sort, After: &cursor})
+		got := make([]types.Id, 0, len(quotes))
+		for _, quote := range quotes {
+			got = append(got, quote.Id)
+		}
+		expected := []types.Id{id1, id3}
+		if !slices.Equal(got, expected) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", got, expected)
+		}
+	})
+}
+
+func TestListSortedByAuthorKey(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Buddha", Quote: "Quote2"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "confucius", Quote: "Quote3"})
+	id4, _ := quotesStore.Create(types.QuoteData{Author: "Жуков", Quote: "Quote4"})
+	id5, _ := quotesStore.Create(types.QuoteData{Author: "Ёлкин", Quote: "Quote5"})
+	id6, _ := quotesStore.Create(types.QuoteData{Author: "Дюма", Quote: "Quote6"})
+	id7, _ := quotesStore.Create(types.QuoteData{Author: "Dante", Quote: "Quote7"})
+
+	sort := types.Sort{Field: types.SortByAuthor}
+	got := make([]types.Id, 0, 7)
+	var after *types.Cursor
+	for {
+		quotes, more, err := quotesStore.List(types.QuotesQuery{Sort: sort, After: after, Limit: 2})
+		if err != nil {
+			t.Fatalf("store returned unexpected error: %v", err)
+		}
+		for _, quote := range quotes {
+			got = append(got, quote.Id)
+		}
+		if !more {
+			break
+		}
+		cursor := types.NewCursor(sort, quotes[len(quotes)-1])
+		after = &cursor
+	}
+
+	expected := []types.Id{id2, id1, id3, id7, id6, id5, id4}
+	if !slices.Equal(got, expected) {
+		t.Errorf("store returned unexpected order: got %v want %v", got, expected)
+	}
+}
+
+func TestListByCreationTime(t *testing.T) {
+	// the clock goes backwards to check that creation time, not id, is used
+	times := []time.Time{testTime.Add(2 * time.Hour), testTime, testTime.Add(time.Hour)}
+	clock := func() time.Time {
+		now := times[0]
+		times = times[1:]
+		return now
+	}
+	quotesStore := newQuotesStore(WithClock(clock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote2"})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote3"})
+
+	testCases := []struct {
+		name     string
+		input    types.QuotesQuery
+		expected []types.Id
+	}{
+		{
+			name:     "CreatedAsc",
+			input:    types.QuotesQuery{Sort: types.Sort{Field: types.SortByCreated}},
+			expected: []types.Id{id2, id3, id1},
+		},
+		{
+			name:     "CreatedDesc",
+			input:    types.QuotesQuery{Sort: types.Sort{Field: types.SortByCreated, Desc: true}},
+			expected: []types.Id{id1, id3, id2},
+		},
+		{
+			name:     "CreatedAfter",
+			input:    types.QuotesQuery{CreatedAfter: testTime},
+			expected: []types.Id{id1, id3},
+		},
+		{
+			name:     "CreatedBefore",
+			input:    types.QuotesQuery{CreatedBefore: testTime.Add(2 * time.Hour)},
+			expected: []types.Id{id2, id3},
+		},
+		{
+			name:     "CreatedBetween",
+			input:    types.QuotesQuery{CreatedAfter: testTime, CreatedBefore: testTime.Add(2 * time.Hour), Limit: 1},
+			expected: []types.Id{id3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotes, _, err := quotesStore.List(tc.input)
+			if err != nil {
+				t.Errorf("store returned unexpected error: %v", err)
+			}
+			got := make([]types.Id, 0, len(quotes))
+			for _, quote := range quotes {
+				got = append(got, quote.Id)
+			}
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("store returned unexpected quotes: got %v want %v", got, tc.expected)
+			}
+		})
+	}
+
+	t.Run("FilteredPageHasMore", func(t *testing.T) {
+		_, more, _ := quotesStore.List(types.QuotesQuery{CreatedAfter: testTime, Limit: 1})
+		if !more {
+			t.Errorf("store returned unexpected more flag: got %v want %v", more, true)
+		}
+	})
+}
+
+func TestListByTags(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote1", Tags: []types.Tag{"life", "love"}})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote2", Tags: []types.Tag{"life"}})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Other", Quote: "Quote3", Tags: []types.Tag{"love", "war"}})
+	quotesStore.Create(types.QuoteData{Author: "Other", Quote: "Quote4"})
+
+	testCases := []struct {
+		name     string
+		input    types.QuotesQuery
+		expected []types.Id
+	}{
+		{
+			name:     "SingleTag",
+			input:    types.QuotesQuery{Tags: []types.Tag{"love"}},
+			expected: []types.Id{id1, id3},
+		},
+		{
+			name:     "AllTags",
+			input:    types.QuotesQuery{Tags: []types.Tag{"life", "love"}},
+			expected: []types.Id{id1},
+		},
+		{
+			name:     "AnyTag",
+			input:    types.QuotesQuery{Tags: []types.Tag{"life", "war"}, AnyTag: true},
+			expected: []types.Id{id1, id2, id3},
+		},
+		{
+			name:     "UnknownTag",
+			input:    types.QuotesQuery{Tags: []types.Tag{"life", "peace"}},
+			expected: []types.Id{},
+		},
+		{
+			name:     "TagAndAuthor",
+			input:    types.QuotesQuery{Author: "Other", Tags: []types.Tag{"love"}},
+			expected: []types.Id{id3},
+		},
+		{
+			name:     "TagSorted",
+			input:    types.QuotesQuery{Tags: []types.Tag{"love"}, Sort: types.Sort{Field: types.SortById, Desc: true}},
+			expected: []types.Id{id3, id1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotes, _, err := quotesStore.List(tc.input)
+			if err != nil {
+				t.Errorf("store returned unexpected error: %v", err)
+			}
+			got := make([]types.Id, 0, len(quotes))
+			for _, quote := range quotes {
+				got = append(got, quote.Id)
+			}
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("store returned unexpected quotes: got %v want %v", got, tc.expected)
+			}
+		})
+	}
+
+	t.Run("TagIndexUpdated", func(t *testing.T) {
+		quotesStore.Update(id2, func(quote *types.QuoteData) error {
+			quote.Tags = []types.Tag{"war"}
+			return nil
+		})
+		quotesStore.Delete(id3, nil, "")
+
+		quotes, _, _ := quotesStore.List(types.QuotesQuery{Tags: []types.Tag{"war"}})
+		if len(quotes) != 1 || quotes[0].Id != id2 {
+			t.Errorf("store returned unexpected quotes: %v", quotes)
+		}
+	})
+}
+
+func TestListByAttribution(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote1", Attribution: types.AttributionVerified})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote2", Attribution: types.AttributionDisputed})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote3"})
+	id4, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote4", Attribution: types.AttributionVerified})
+
+	verified, unverified := true, false
+	testCases := []struct {
+		name     string
+		input    types.QuotesQuery
+		expected []types.Id
+	}{
+		{
+			name:     "Verified",
+			input:    types.QuotesQuery{Verified: &verified},
+			expected: []types.Id{id1, id4},
+		},
+		{
+			name:     "Unverified",
+			input:    types.QuotesQuery{Verified: &unverified},
+			expected: []types.Id{id2, id3},
+		},
+		{
+			name:     "Disputed",
+			input:    types.QuotesQuery{Attribution: types.AttributionDisputed},
+			expected: []types.Id{id2},
+		},
+		{
+			name:     "VerifiedPage",
+			input:    types.QuotesQuery{Verified: &verified, Sort: types.Sort{Field: types.SortByCreated, Desc: true}, Limit: 1},
+			expected: []types.Id{id4},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quotes, _, err := quotesStore.List(tc.input)
+			if err != nil {
+				t.Errorf("store returned unexpected error: %v", err)
+			}
+			got := make([]types.Id, 0, len(quotes))
+			for _, quote := range quotes {
+				got = append(got, quote.Id)
+			}
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("store returned unexpected quotes: got %v want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote1", Tags: []types.Tag{"life", "love"}})
+	quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote2", Tags: []types.Tag{"war"}})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote3", Tags: []types.Tag{"love", "war"}})
+	quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote4", Tags: []types.Tag{"love"}})
+
+	t.Run("CountsOrdered", func(t *testing.T) {
+		tags, err := quotesStore.GetTags()
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		expected := []types.TagCount{{Tag: "love", Count: 3}, {Tag: "war", Count: 2}, {Tag: "life", Count: 1}}
+		if !slices.Equal(tags, expected) {
+			t.Errorf("store returned unexpected tags: got %v want %v", tags, expected)
+		}
+	})
+
+	t.Run("UnusedTagsDropped", func(t *testing.T) {
+		quotesStore.Delete(id3, nil, "")
+		quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote5"})
+
+		tags, _ := quotesStore.GetTags()
+		expected := []types.TagCount{{Tag: "love", Count: 2}, {Tag: "life", Count: 1}, {Tag: "war", Count: 1}}
+		if !slices.Equal(tags, expected) {
+			t.Errorf("store returned unexpected tags: got %v want %v", tags, expected)
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "To be, or not to be"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Love all, trust a few"})
+
+	searchIds := func(q string) []types.Id {
+		query, err := search.ParseQuery(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hits, err := quotesStore.Search(query, 10)
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		ids := make([]types.Id, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.Quote.Id)
+		}
+		return ids
+	}
+
+	t.Run("Found", func(t *testing.T) {
+		query, _ := search.ParseQuery("trust")
+		hits, _ := quotesStore.Search(query, 10)
+		if len(hits) != 1 || hits[0].Quote.Id != id2 || hits[0].Snippet != "Love all, <mark>trust</mark> a few" {
+			t.Errorf("store returned unexpected hits: %v", hits)
+		}
+	})
+
+	t.Run("IndexUpdated", func(t *testing.T) {
+		quotesStore.Update(id1, func(quote *types.QuoteData) error {
+			quote.Quote = "Trust, but verify"
+			return nil
+		})
+		if got := searchIds("be"); len(got) != 0 {
+			t.Errorf("store returned unexpected quotes: %v", got)
+		}
+		if got := searchIds("trust"); !slices.Equal(got, []types.Id{id1, id2}) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", got, []types.Id{id1, id2})
+		}
+	})
+
+	t.Run("IndexAfterDelete", func(t *testing.T) {
+		quotesStore.Delete(id1, nil, "")
+		if got := searchIds("trust"); !slices.Equal(got, []types.Id{id2}) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", got, []types.Id{id2})
+		}
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	t.Run("EmptyStore", func(t *testing.T) {
+		_, err := quotesStore.Update(types.Id(1), func(quote *types.QuoteData) error {
+			return nil
+		})
+		if err != ErrQuoteNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrQuoteNotFound)
+		}
+	})
+
+	var (
+		author1 types.Author = "Author1"
+		quote1  types.Quote  = "Quote1"
+		author2 types.Author = "Author2"
+		quote2  types.Quote  = "Quote2"
+	)
+	id1, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: quote1})
+
+	t.Run("FailedModification", func(t *testing.T) {
+		expectedError := fmt.Errorf("modification failed")
+		_, err := quotesStore.Update(id1, func(quote *types.QuoteData) error {
+			quote.Quote = quote2
+			return expectedError
+		})
+		if err != expectedError {
+			t.Errorf("store returned unexpected error: got %v want %v", err, expectedError)
+		}
+
+		record := quotesStore.quotes[quotesStore.positions[id1]]
+		if record.Quote != quote1 {
+			t.Errorf("store saved failed modification: got %v want %v", record.Quote, quote1)
+		}
+	})
+
+	expectedQuote := types.QuoteData{Id: id1, Author: author2, Quote: quote2, AuthorId: 2, CreatedAt: testTime, UpdatedAt: testTime, Version: 2}
+	t.Run("CorrectId", func(t *testing.T) {
+		quote, err := quotesStore.Update(id1, func(quote *types.QuoteData) error {
+			quote.Id = 42
+			quote.Version = 42
+			quote.Author = author2
+			quote.Quote = quote2
+			return nil
+		})
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(quote, expectedQuote) {
+			t.Errorf("store returned unexpected quote: got %v want %v", quote, expectedQuote)
+		}
+
+		record := quotesStore.quotes[quotesStore.positions[id1]]
+		if !reflect.DeepEqual(record, expectedQuote) {
+			t.Errorf("store saved unexpected quote: got %v want %v", record, expectedQuote)
+		}
+	})
+
+	t.Run("Timestamps", func(t *testing.T) {
+		now := testTime
+		clock := func() time.Time {
+			now = now.Add(time.Minute)
+			return now
+		}
+		quotesStore := newQuotesStore(WithClock(clock))
+
+		id, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: quote1})
+		quote, err := quotesStore.Update(id, func(quote *types.QuoteData) error {
+			quote.CreatedAt = time.Time{}
+			quote.Quote = quote2
+			return nil
+		})
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+
+		expectedCreatedAt, expectedUpdatedAt := testTime.Add(time.Minute), testTime.Add(2*time.Minute)
+		if !quote.CreatedAt.Equal(expectedCreatedAt) {
+			t.Errorf("store returned unexpected creation time: got %v want %v", quote.CreatedAt, expectedCreatedAt)
+		}
+		if !quote.UpdatedAt.Equal(expectedUpdatedAt) {
+			t.Errorf("store returned unexpected modification time: got %v want %v", quote.UpdatedAt, expectedUpdatedAt)
+		}
+	})
+
+	t.Run("AuthorIndexUpdated", func(t *testing.T) {
+		quotes, _ := quotesStore.GetByAuthor(author1)
+		if len(quotes) != 0 {
+			t.Errorf("store returned unexpected quotes for old author: %v", quotes)
+		}
+
+		quotes, _ = quotesStore.GetByAuthor(author2)
+		if !reflect.DeepEqual(quotes, []types.QuoteData{expectedQuote}) {
+			t.Errorf("store returned unexpected quotes for new author: got %v want %v", quotes, []types.QuoteData{expectedQuote})
+		}
+	})
+}
+
+func TestDelete(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	expectedError := fmt.Errorf("no quote with specified id")
+	t.Run("EmptyStore", func(t *testing.T) {
+		err := quotesStore.Delete(types.Id(1), nil, "")
+		if err == nil || err.Error() != expectedError.Error() {
+			t.Errorf("store returned unexpected error: got %v want %v", err, expectedError)
+		}
+	})
+
+	var (
+		author1 types.Author = "Author1"
+		quote1  types.Quote  = "Quote1"
+		author2 types.Author = "Author2"
+		quote2  types.Quote  = "Quote2"
+	)
+	id1, _ := quotesStore.Create(types.QuoteData{Author: author1, Quote: quote1})
+	quotesStore.Create(types.QuoteData{Author: author2, Quote: quote2})
+
+	t.Run("WrongId", func(t *testing.T) {
+		err := quotesStore.Delete(types.Id(50), nil, "")
+		if err == nil || err.Error() != expectedError.Error() {
+			t.Errorf("store returned unexpected error: got %v want %v", err, expectedError)
+		}
+	})
+
+	t.Run("StaleVersion", func(t *testing.T) {
+		err := quotesStore.Delete(id1, types.Versions{2}, "")
+		if err != ErrVersionMismatch {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrVersionMismatch)
+		}
+	})
+
+	t.Run("CorrectId", func(t *testing.T) {
+		err := quotesStore.Delete(id1, types.Versions{1}, "")
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+
+		_, ok := quotesStore.positions[id1]
+		if ok {
+			t.Errorf("store did not delete quote with id = %v", id1)
+		}
+	})
+}
+
+func TestDeleteKeepsPositions(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	ids := make([]types.Id, 0, 5)
+	for i := 0; i < 5; i++ {
+		id, _ := quotesStore.Create(types.QuoteData{Author: types.Author(fmt.Sprintf("Author%d", i)), Quote: types.Quote(fmt.Sprintf("Quote%d", i))})
+		ids = append(ids, id)
+	}
+	quotesStore.Delete(ids[1], nil, "")
+	quotesStore.Delete(ids[4], nil, "")
+	quotesStore.Delete(ids[0], nil, "")
+
+	t.Run("PositionsMatchQuotes", func(t *testing.T) {
+		if len(quotesStore.positions) != len(quotesStore.quotes) {
+			t.Errorf("store has inconsistent index: %v positions for %v quotes", len(quotesStore.positions), len(quotesStore.quotes))
+		}
+		for id, pos := range quotesStore.positions {
+			if quotesStore.quotes[pos].Id != id {
+				t.Errorf("store has wrong position for id = %v: quote at %v has id = %v", id, pos, quotesStore.quotes[pos].Id)
+			}
+		}
+	})
+}
+
+func TestTrash(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Author1", Quote: "Quote1"})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Author1", Quote: "Quote2"})
+	quotesStore.Delete(id1, nil, "")
+
+	deletedAt := testTime
+	expectedTrash := []types.QuoteData{{Id: id1, Author: "Author1", Quote: "Quote1", AuthorId: 1, CreatedAt: testTime, UpdatedAt: testTime, Version: 2, DeletedAt: &deletedAt}}
+	t.Run("MovedToTrash", func(t *testing.T) {
+		quotes, err := quotesStore.GetTrash()
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(quotes, expectedTrash) {
+			t.Errorf("store returned unexpected quotes: got %v want %v", quotes, expectedTrash)
+		}
+	})
+
+	t.Run("ExcludedFromReads", func(t *testing.T) {
+		quotes, _ := quotesStore.GetAll()
+		if len(quotes) != 1 || quotes[0].Id != id2 {
+			t.Errorf("store returned unexpected quotes: %v", quotes)
+		}
+
+		quotes, _ = quotesStore.GetByAuthor("Author1")
+		if len(quotes) != 1 || quotes[0].Id != id2 {
+			t.Errorf("store returned unexpected quotes: %v", quotes)
+		}
+
+		for i := 0; i < 10; i++ {
+			quote, _ := quotesStore.GetRandom()
+			if quote.Id != id2 {
+				t.Fatalf("store returned quote from trash: %v", quote)
+			}
+		}
+
+		_, err := quotesStore.GetById(id1)
+		if err != ErrQuoteNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrQuoteNotFound)
+		}
+
+		author, _ := quotesStore.GetAuthorById(1)
+		if author.QuoteCount != 1 {
+			t.Errorf("store returned unexpected quote count: got %v want %v", author.QuoteCount, 1)
+		}
+	})
+
+	testCases := []struct {
+		name          string
+		id            types.Id
+		expectedError error
+	}{
+		{
+			name:          "NotInTrash",
+			id:            id2,
+			expectedError: ErrQuoteNotFound,
+		},
+		{
+			name:          "WrongId",
+			id:            types.Id(50),
+			expectedError: ErrQuoteNotFound,
+		},
+		{
+			name: "CorrectId",
+			id:   id1,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run("Restore"+testCase.name, func(t *testing.T) {
+			quote, err := quotesStore.Restore(testCase.id, "")
+			if err != testCase.expectedError {
+				t.Fatalf("store returned unexpected error: got %v want %v", err, testCase.expectedError)
+			}
+			if err != nil {
+				return
+			}
+			if quote.DeletedAt != nil {
+				t.Errorf("store returned quote with deletion time: %v", quote)
+			}
+			if quote.Version != 3 {
+				t.Errorf("store returned unexpected version: got %v want %v", quote.Version, 3)
+			}
+
+			restored, err := quotesStore.GetById(testCase.id)
+			if err != nil || !reflect.DeepEqual(restored, quote) {
+				t.Errorf("store returned unexpected quote: got %v want %v", restored, quote)
+			}
+			trash, _ := quotesStore.GetTrash()
+			if len(trash) != 0 {
+				t.Errorf("store kept restored quote in trash: %v", trash)
+			}
+		})
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	now := testTime
+	quotesStore := newQuotesStore(WithClock(func() time.Time { return now }))
+
+	ids := make([]types.Id, 0, 3)
+	for i := 0; i < 3; i++ {
+		id, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: types.Quote(fmt.Sprintf("Quote%d", i))})
+		quotesStore.Delete(id, nil, "")
+		ids = append(ids, id)
+		now = now.Add(time.Hour)
+	}
+	live, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote"})
+
+	t.Run("PurgeLiveQuote", func(t *testing.T) {
+		err := quotesStore.Purge(live)
+		if err != ErrQuoteNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrQuoteNotFound)
+		}
+	})
+
+	t.Run("PurgeOne", func(t *testing.T) {
+		err := quotesStore.Purge(ids[2])
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+
+		_, err = quotesStore.Restore(ids[2], "")
+		if err != ErrQuoteNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrQuoteNotFound)
+		}
+	})
+
+	testCases := []struct {
+		name           string
+		deletedBefore  time.Time
+		expectedPurged int
+		expectedTrash  []types.Id
+	}{
+		{
+			name:           "NothingExpired",
+			deletedBefore:  testTime,
+			expectedPurged: 0,
+			expectedTrash:  []types.Id{ids[0], ids[1]},
+		},
+		{
+			name:           "OldestExpired",
+			deletedBefore:  testTime.Add(time.Minute),
+			expectedPurged: 1,
+			expectedTrash:  []types.Id{ids[1]},
+		},
+		{
+			name:           "All",
+			expectedPurged: 1,
+			expectedTrash:  []types.Id{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			purged, err := quotesStore.PurgeTrash(testCase.deletedBefore)
+			if err != nil {
+				t.Errorf("store returned unexpected error: %v", err)
+			}
+			if purged != testCase.expectedPurged {
+				t.Errorf("store purged unexpected number of quotes: got %v want %v", purged, testCase.expectedPurged)
+			}
+
+			trash, _ := quotesStore.GetTrash()
+			trashIds := make([]types.Id, 0, len(trash))
+			for _, quote := range trash {
+				trashIds = append(trashIds, quote.Id)
+			}
+			if !reflect.DeepEqual(trashIds, testCase.expectedTrash) {
+				t.Errorf("store kept unexpected quotes in trash: got %v want %v", trashIds, testCase.expectedTrash)
+			}
+		})
+	}
+
+	t.Run("LiveQuoteKept", func(t *testing.T) {
+		quotes, _ := quotesStore.GetAll()
+		if len(quotes) != 1 || quotes[0].Id != live {
+			t.Errorf("store returned unexpected quotes: %v", quotes)
+		}
+	})
+}
+
+func TestGetRandomUniform(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	const quotesNumber = 4
+	for i := 0; i < quotesNumber; i++ {
+		quotesStore.Create(types.QuoteData{Author: "Author", Quote: types.Quote(fmt.Sprintf("Quote%d", i))})
+	}
+	id, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote"})
+	quotesStore.Delete(id, nil, "")
+
+	const draws = 40000
+	counts := make(map[types.Id]int)
+	for i := 0; i < draws; i++ {
+		quote, _ := quotesStore.GetRandom()
+		counts[quote.Id]++
+	}
+
+	expected := draws / quotesNumber
+	for id, count := range counts {
+		if count < expected*9/10 || count > expected*11/10 {
+			t.Errorf("store returned quote with id = %v unexpectedly often: got %v want about %v", id, count, expected)
+		}
+	}
+}
+
+// mapWalkRandom is the previous GetRandom implementation, kept to compare with.
+func mapWalkRandom(data map[types.Id]types.QuoteData) types.QuoteData {
+	randomIdx := rand.Intn(len(data))
+	for _, quote := range data {
+		if randomIdx == 0 {
+			return quote
+		}
+		randomIdx--
+	}
+
+	return types.QuoteData{}
+}
+
+func BenchmarkGetRandom(b *testing.B) {
+	const quotesNumber = 1_000_000
+
+	quotesStore := newQuotesStore(WithClock(testClock))
+	data := make(map[types.Id]types.QuoteData, quotesNumber)
+	for i := 0; i < quotesNumber; i++ {
+		id, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: types.Quote(fmt.Sprintf("Quote%d", i))})
+		data[id] = types.QuoteData{Id: id, Author: "Author", Quote: types.Quote(fmt.Sprintf("Quote%d", i)), CreatedAt: testTime, UpdatedAt: testTime, Version: 1}
+	}
+
+	b.Run("DenseSlice", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			quotesStore.GetRandom()
+		}
+	})
+
+	b.Run("MapWalk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mapWalkRandom(data)
+		}
+	})
+}
+
+func BenchmarkGetByAuthor(b *testing.B) {
+	const quotesNumber = 1_000_000
+
+	quotesStore := newQuotesStore(WithClock(testClock))
+	for i := 0; i < quotesNumber; i++ {
+		quotesStore.Create(types.QuoteData{Author: types.Author(fmt.Sprintf("Author%d", i%10_000)), Quote: types.Quote(fmt.Sprintf("Quote%d", i))})
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		quotesStore.GetByAuthor("Author42")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	const (
+		workers    = 8
+		iterations = 500
+	)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				id, err := quotesStore.Create(types.QuoteData{Author: types.Author(fmt.Sprintf("Author%d", i%3)), Quote: types.Quote(fmt.Sprintf("Quote%d-%d", w, i))})
+				if err != nil {
+					t.Errorf("store returned unexpected error: %v", err)
+					return
+				}
+				if i%2 == 0 {
+					quotesStore.Delete(id, nil, "")
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				quotesStore.GetAll()
+				quotesStore.GetByAuthor("Author1")
+				quotesStore.GetRandom()
+			}
+		}()
+	}
+	wg.Wait()
+
+	t.Run("ConsistentState", func(t *testing.T) {
+		expected := workers * iterations / 2
+		quotes, _ := quotesStore.GetAll()
+		if len(quotes) != expected {
+			t.Errorf("store returned unexpected number of quotes: got %v want %v", len(quotes), expected)
+		}
+
+		byAuthor := 0
+		for i := 0; i < 3; i++ {
+			quotes, _ := quotesStore.GetByAuthor(types.Author(fmt.Sprintf("Author%d", i)))
+			byAuthor += len(quotes)
+		}
+		if byAuthor != expected {
+			t.Errorf("store has inconsistent author index: got %v quotes want %v", byAuthor, expected)
+		}
+	})
+}
+
+// exclusiveStore serializes reads the way the store did before they were
+// allowed to run concurrently, kept to compare with.
+type exclusiveStore struct {
+	mtx sync.Mutex
+	QuotesStore
+}
+
+func (es *exclusiveStore) GetByAuthor(author types.Author) ([]types.QuoteData, error) {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+
+	return es.QuotesStore.GetByAuthor(author)
+}
+
+func (es *exclusiveStore) GetRandom() (types.QuoteData, error) {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+
+	return es.QuotesStore.GetRandom()
+}
+
+func BenchmarkParallelReads(b *testing.B) {
+	const quotesNumber = 100_000
+
+	quotesStore := newQuotesStore(WithClock(testClock))
+	for i := 0; i < quotesNumber; i++ {
+		quotesStore.Create(types.QuoteData{Author: types.Author(fmt.Sprintf("Author%d", i%1_000)), Quote: types.Quote(fmt.Sprintf("Quote%d", i))})
+	}
+
+	benchmarks := []struct {
+		name  string
+		store QuotesStore
+	}{
+		{name: "RWMutex", store: quotesStore},
+		{name: "Mutex", store: &exclusiveStore{QuotesStore: quotesStore}},
+	}
+
+	for _, s := range benchmarks {
+		b.Run(s.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if i%2 == 0 {
+						s.store.GetByAuthor("Author42")
+					} else {
+						s.store.GetRandom()
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	now := testTime
+	quotesStore := newQuotesStore(WithClock(func() time.Time { return now }))
+
+	id, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote", UpdatedBy: "alice"})
+	now = now.Add(time.Hour)
+	quotesStore.Update(id, func(quote *types.QuoteData) error {
+		quote.Quote = "Quote2"
+		quote.Tags = []types.Tag{"life"}
+		quote.UpdatedBy = "bob"
+		return nil
+	})
+	now = now.Add(time.Hour)
+	// nothing is changed, so no revision is saved
+	quotesStore.Update(id, func(quote *types.QuoteData) error {
+		quote.UpdatedBy = "carol"
+		return nil
+	})
+
+	expected := []types.Revision{
+		{QuoteId: id, Number: 1, Author: "Author", Quote: "Quote", Actor: "alice", CreatedAt: testTime},
+		{QuoteId: id, Number: 2, Author: "Author", Quote: "Quote2", Tags: []types.Tag{"life"}, Actor: "bob", CreatedAt: testTime.Add(time.Hour)},
+	}
+	t.Run("Saved", func(t *testing.T) {
+		revisions, err := quotesStore.GetRevisions(id)
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(revisions, expected) {
+			t.Errorf("store returned unexpected revisions: got %v want %v", revisions, expected)
+		}
+	})
+
+	t.Run("KeptInTrash", func(t *testing.T) {
+		quotesStore.Delete(id, nil, "")
+		defer quotesStore.Restore(id, "")
+
+		revisions, err := quotesStore.GetRevisions(id)
+		if err != nil || len(revisions) != len(expected) {
+			t.Errorf("store returned unexpected revisions: %v, %v", revisions, err)
+		}
+	})
+
+	t.Run("WrongId", func(t *testing.T) {
+		_, err := quotesStore.GetRevisions(types.Id(50))
+		if err != ErrQuoteNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrQuoteNotFound)
+		}
+	})
+
+	t.Run("SavedOnMerge", func(t *testing.T) {
+		quotesStore.Create(types.QuoteData{Author: "Other", Quote: "Quote3"})
+		quotesStore.MergeAuthors(1, 2, "")
+
+		revisions, _ := quotesStore.GetRevisions(id)
+		last := revisions[len(revisions)-1]
+		if len(revisions) != 3 || last.Author != "Other" || last.Actor != "" {
+			t.Errorf("store returned unexpected revisions: %v", revisions)
+		}
+	})
+
+	t.Run("RemovedOnPurge", func(t *testing.T) {
+		quotesStore.Delete(id, nil, "")
+		quotesStore.Purge(id)
+
+		_, ok := quotesStore.revisions[id]
+		if ok {
+			t.Errorf("store kept revisions of purged quote with id = %v", id)
+		}
+	})
+}
+
+func TestRevisionsOfLegacyQuote(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	// quotes saved before revisions were introduced have no history
+	quotesStore.apply(change{CurrId: 1, Put: []types.QuoteData{{Id: 1, Author: "Author", Quote: "Quote"}}})
+
+	expected := []types.Revision{{QuoteId: 1, Number: 1, Author: "Author", Quote: "Quote"}}
+	t.Run("CurrentContent", func(t *testing.T) {
+		revisions, _ := quotesStore.GetRevisions(1)
+		if !reflect.DeepEqual(revisions, expected) {
+			t.Errorf("store returned unexpected revisions: got %v want %v", revisions, expected)
+		}
+	})
+
+	quotesStore.Update(1, func(quote *types.QuoteData) error {
+		quote.Quote = "Quote2"
+		return nil
+	})
+	expected = append(expected, types.Revision{QuoteId: 1, Number: 2, Author: "Author", Quote: "Quote2", CreatedAt: testTime})
+	t.Run("HistoryStarted", func(t *testing.T) {
+		revisions, _ := quotesStore.GetRevisions(1)
+		if !reflect.DeepEqual(revisions, expected) {
+			t.Errorf("store returned unexpected revisions: got %v want %v", revisions, expected)
+		}
+	})
+}
+
+func TestDuplicates(t *testing.T) {
+	quotesStore := newQuotesStore(WithClock(testClock))
+
+	id1, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "Life is really simple, but we insist on making it complicated."})
+	id2, _ := quotesStore.Create(types.QuoteData{Author: "Someone", Quote: "Life is really simple, but men insist on making it complicated."})
+	id3, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "It does not matter how slowly you go as long as you do not stop."})
+
+	t.Run("ExactDuplicate", func(t *testing.T) {
+		_, err := quotesStore.Create(types.QuoteData{Author: "confucius", Quote: "life is really simple but we insist on making it complicated!"})
+		var duplicate DuplicateQuoteError
+		if !errors.As(err, &duplicate) || duplicate.Id != id1 {
+			t.Errorf("store returned unexpected error: got %v want %v", err, DuplicateQuoteError{Id: id1})
+		}
+		if !errors.Is(err, ErrDuplicateQuote) {
+			t.Errorf("store returned error not matching %v", ErrDuplicateQuote)
+		}
+	})
+
+	t.Run("OtherAuthor", func(t *testing.T) {
+		id, err := quotesStore.Create(types.QuoteData{Author: "Someone", Quote: "It does not matter how slowly you go as long as you do not stop."})
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		quotesStore.Delete(id, nil, "")
+		quotesStore.Purge(id)
+	})
+
+	t.Run("NearDuplicates", func(t *testing.T) {
+		duplicates, err := quotesStore.GetDuplicates(id1, types.DefaultDuplicateThreshold)
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if len(duplicates) != 1 || duplicates[0].Quote.Id != id2 || duplicates[0].Similarity < types.DefaultDuplicateThreshold {
+			t.Errorf("store returned unexpected duplicates: %v", duplicates)
+		}
+	})
+
+	t.Run("NoDuplicates", func(t *testing.T) {
+		duplicates, err := quotesStore.GetDuplicates(id3, types.DefaultDuplicateThreshold)
+		if err != nil || len(duplicates) != 0 {
+			t.Errorf("store returned unexpected duplicates: %v, %v", duplicates, err)
+		}
+	})
+
+	t.Run("DuplicateInTrash", func(t *testing.T) {
+		quotesStore.Delete(id1, nil, "")
+		defer quotesStore.Restore(id1, "")
+
+		duplicates, err := quotesStore.GetDuplicates(id2, types.DefaultDuplicateThreshold)
+		if err != nil || len(duplicates) != 0 {
+			t.Errorf("store returned unexpected duplicates: %v, %v", duplicates, err)
+		}
+		_, err = quotesStore.GetDuplicates(id1, types.DefaultDuplicateThreshold)
+		if err != ErrQuoteNotFound {
+			t.Errorf("store returned unexpected error: got %v want %v", err, ErrQuoteNotFound)
+		}
+	})
+
+	t.Run("ChangedText", func(t *testing.T) {
+		quotesStore.Update(id2, func(quote *types.QuoteData) error {
+			quote.Quote = "Simplicity is the ultimate sophistication."
+			return nil
+		})
+
+		duplicates, _ := quotesStore.GetDuplicates(id1, types.DefaultDuplicateThreshold)
+		if len(duplicates) != 0 {
+			t.Errorf("store returned unexpected duplicates: %v", duplicates)
+		}
+	})
+
+	t.Run("UpdatedIntoDuplicate", func(t *testing.T) {
+		_, err := quotesStore.Update(id3, func(quote *types.QuoteData) error {
+			quote.Quote = "Life is really simple, but we insist on making it complicated"
+			return nil
+		})
+		if err != (DuplicateQuoteError{Id: id1}) {
+			t.Errorf("store returned unexpected error: got %v want %v", err, DuplicateQuoteError{Id: id1})
+		}
+
+		quote, _ := quotesStore.GetById(id3)
+		if quote.Quote != "It does not matter how slowly you go as long as you do not stop." {
+			t.Errorf("store saved duplicate quote: %v", quote)
+		}
+	})
+
+	t.Run("RestoredDuplicate", func(t *testing.T) {
+		quotesStore.Delete(id3, nil, "")
+		id, _ := quotesStore.Create(types.QuoteData{Author: "Confucius", Quote: "It does not matter how slowly you go, as long as you do not stop!"})
+
+		_, err := quotesStore.Restore(id3, "")
+		if err != (DuplicateQuoteError{Id: id}) {
+			t.Errorf("store returned unexpected error: got %v want %v", err, DuplicateQuoteError{Id: id})
+		}
+
+		quotesStore.Delete(id, nil, "")
+		quotesStore.Purge(id)
+		_, err = quotesStore.Restore(id3, "")
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+	})
+
+	t.Run("MergedDuplicates", func(t *testing.T) {
+		id, _ := quotesStore.Create(types.QuoteData{Author: "Kong Fuzi", Quote: "It does not matter how slowly you go as long as you do not stop."})
+		quotesStore.MergeAuthors(3, 1, "")
+
+		duplicates, _ := quotesStore.GetDuplicates(id, 1)
+		if len(duplicates) != 1 || duplicates[0].Quote.Id != id3 {
+			t.Errorf("store returned unexpected duplicates: %v", duplicates)
+		}
+
+		// duplicates are kept while their texts are not changed
+		_, err := quotesStore.Update(id, func(quote *types.QuoteData) error {
+			quote.Tags = []types.Tag{"life"}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+	})
+}
+
+func TestActorOfTrashAndMerge(t *testing.T) {
+	now := testTime
+	quotesStore := newQuotesStore(WithClock(func() time.Time { return now }))
+
+	id, _ := quotesStore.Create(types.QuoteData{Author: "Author", Quote: "Quote", UpdatedBy: "alice"})
+	quotesStore.Create(types.QuoteData{Author: "Other", Quote: "Quote2"})
+
+	t.Run("Delete", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		quotesStore.Delete(id, nil, "bob")
+
+		trash, _ := quotesStore.GetTrash()
+		if len(trash) != 1 || trash[0].UpdatedBy != "bob" || !trash[0].UpdatedAt.Equal(now) {
+			t.Errorf("store returned unexpected quotes: %v", trash)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		quote, err := quotesStore.Restore(id, "carol")
+		if err != nil {
+			t.Errorf("store returned unexpected error: %v", err)
+		}
+		if quote.UpdatedBy != "carol" || !quote.UpdatedAt.Equal(now) {
+			t.Errorf("store returned unexpected quote: %v", quote)
+		}
+
+		// the content is not changed, so there are no new revisions
+		revisions, _ := quotesStore.GetRevisions(id)
+		if len(revisions) != 1 {
+			t.Errorf("store returned unexpected revisions: %v", revisions)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		quotesStore.MergeAuthors(1, 2, "dave")
+
+		quote, _ := quotesStore.GetById(id)
+		if quote.UpdatedBy != "dave" {
+			t.Errorf("store returned unexpected quote: %v", quote)
+		}
+		revisions, _ := quotesStore.GetRevisions(id)
+		if len(revisions) != 2 || revisions[1].Actor != "dave" {
+			t.Errorf("store returned unexpected revisions: %v", revisions)
 		}
 	})
 }
@@ -0,0 +1,69 @@
+package stores
+
+import (
+	"slices"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/types"
+)
+
+func (qs *quotesStore) GetRevisions(id types.Id) ([]types.Revision, error) {
+	qs.mtx.RLock()
+	defer qs.mtx.RUnlock()
+
+	quote, ok := qs.quote(id)
+	if !ok {
+		return nil, ErrQuoteNotFound
+	}
+
+	revisions, ok := qs.revisions[id]
+	if !ok {
+		return []types.Revision{types.NewRevision(quote, 1)}, nil
+	}
+
+	return slices.Clone(revisions), nil
+}
+
+// quote returns the quote with specified id, which may be in the trash.
+func (qs *quotesStore) quote(id types.Id) (types.QuoteData, bool) {
+	pos, ok := qs.positions[id]
+	if ok {
+		return qs.quotes[pos], true
+	}
+
+	quote, ok := qs.trash[id]
+	return quote, ok
+}
+
+// revise returns revisions to be saved when old is replaced by quote: none if
+// the content has not changed. A quote without history gets its old content
+// saved as the first revision.
+func (qs *quotesStore) revise(old, quote types.QuoteData) []types.Revision {
+	revisions := make([]types.Revision, 0, 2)
+
+	history := qs.revisions[old.Id]
+	last := types.NewRevision(old, 1)
+	if len(history) == 0 {
+		revisions = append(revisions, last)
+	} else {
+		last = history[len(history)-1]
+	}
+
+	revision := types.NewRevision(quote, last.Number+1)
+	if len(last.Diff(revision)) == 0 {
+		return revisions
+	}
+
+	return append(revisions, revision)
+}
+
+// putRevision adds revision to the history of its quote replacing a revision
+// with the same number, so snapshots can be replayed over the log.
+func (qs *quotesStore) putRevision(revision types.Revision) {
+	history := qs.revisions[revision.QuoteId]
+	if revision.Number <= len(history) {
+		history[revision.Number-1] = revision
+		return
+	}
+
+	qs.revisions[revision.QuoteId] = append(history, revision)
+}
@@ -0,0 +1,76 @@
+// Package translit matches names written in cyrillic and latin scripts.
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// russian maps russian letters to latin ones, mostly following the BGN/PCGN
+// romanization used by english texts.
+var russian = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// spellings replaces letter combinations which differ between popular
+// romanizations of the same russian letters with a single form.
+var spellings = strings.NewReplacer(
+	"tch", "ch", // Tchaikovsky
+	"kh", "h", // Chekhov, Chehov
+	"x", "ks", // Maxim
+	"w", "v", // Wladimir
+	"j", "y", // Tolstoj
+	"iya", "ia", // Mariya, Maria
+	"ye", "e", // Dostoyevsky
+	"yo", "e", // Fyodor
+)
+
+// Latin transliterates russian letters of lowercase s into latin ones. Other
+// characters are kept as is.
+func Latin(s string) string {
+	builder := strings.Builder{}
+	for _, r := range s {
+		latin, ok := russian[r]
+		if ok {
+			builder.WriteString(latin)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// Key returns a latin form of lowercase s, which is the same for common
+// spellings of a russian name in both scripts: "толстой", "tolstoy" and
+// "tolstoj" all give "tolstoi". Keys are only meant to be compared with each
+// other, they are not readable transliterations.
+func Key(s string) string {
+	s = spellings.Replace(Latin(s))
+
+	// "y" which is not followed by a vowel is "й" or "ы" and is often spelled
+	// as "i": Tolstoi, Dostoevskii
+	runes := make([]rune, 0, len(s))
+	for i, r := range s {
+		if r == '\'' || r == '’' || r == 'ʹ' {
+			continue
+		}
+		if r == 'y' && !strings.ContainsAny(s[i+1:min(i+2, len(s))], "aeiou") {
+			r = 'i'
+		}
+		// "ii" is spelled as "y" as often as "ij" or "iy"
+		if r == 'i' && len(runes) > 0 && runes[len(runes)-1] == 'i' {
+			continue
+		}
+		if unicode.IsSpace(r) && len(runes) > 0 && unicode.IsSpace(runes[len(runes)-1]) {
+			continue
+		}
+		runes = append(runes, r)
+	}
+
+	return string(runes)
+}
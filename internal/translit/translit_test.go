@@ -0,0 +1,63 @@
+package translit
+
+import "testing"
+
+func TestLatin(t *testing.T) {
+	testCases := map[string]string{
+		"лев толстой":     "lev tolstoy",
+		"фёдор":           "fedor",
+		"щедрин":          "shchedrin",
+		"гоголь":          "gogol",
+		"цветаева":        "tsvetaeva",
+		"confucius":       "confucius",
+		"салтыков-щедрин": "saltykov-shchedrin",
+	}
+
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			latin := Latin(input)
+			if latin != expected {
+				t.Errorf("unexpected transliteration of %q: got %q want %q", input, latin, expected)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	testCases := []struct {
+		name      string
+		spellings []string
+	}{
+		{name: "Tolstoy", spellings: []string{"толстой", "tolstoy", "tolstoi", "tolstoj"}},
+		{name: "Dostoevsky", spellings: []string{"достоевский", "dostoevsky", "dostoyevsky", "dostoevskii", "dostoevskij"}},
+		{name: "Chekhov", spellings: []string{"чехов", "chekhov", "tchekhov", "chehov"}},
+		{name: "Tchaikovsky", spellings: []string{"чайковский", "tchaikovsky", "chaikovsky", "chaykovskiy"}},
+		{name: "Fyodor", spellings: []string{"фёдор", "федор", "fyodor", "fedor"}},
+		{name: "Gogol", spellings: []string{"гоголь", "gogol", "gogol'"}},
+		{name: "Gorky", spellings: []string{"горький", "gorky", "gorkiy", "gor'kii"}},
+		{name: "Maria", spellings: []string{"мария", "mariya", "maria"}},
+		{name: "Maxim", spellings: []string{"максим", "maxim", "maksim"}},
+		{name: "Solzhenitsyn", spellings: []string{"солженицын", "solzhenitsyn"}},
+		{name: "FullName", spellings: []string{"лев толстой", "lev tolstoy", "lev  tolstoj"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := Key(tc.spellings[0])
+			for _, spelling := range tc.spellings[1:] {
+				key := Key(spelling)
+				if key != expected {
+					t.Errorf("unexpected key of %q: got %q want %q", spelling, key, expected)
+				}
+			}
+		})
+	}
+
+	t.Run("DifferentNames", func(t *testing.T) {
+		for _, names := range [][2]string{{"толстой", "толстая"}, {"пушкин", "pashkin"}, {"чехов", "cechov"}} {
+			if Key(names[0]) == Key(names[1]) {
+				t.Errorf("different names %q and %q got the same key %q", names[0], names[1], Key(names[0]))
+			}
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxAuthorYear = 9999
+	maxBioLength  = 2000
+)
+
+// AuthorData is a person quotes are attributed to. Quotes are linked to the
+// author whose name or one of aliases has the same Author.Key as the author
+// of the quote. Years before the Common Era are negative, zero means unknown.
+type AuthorData struct {
+	Id         Id       `json:"id"`
+	Name       Author   `json:"name"`
+	Aliases    []Author `json:"aliases,omitempty"`
+	BirthYear  int      `json:"birth_year,omitempty"`
+	DeathYear  int      `json:"death_year,omitempty"`
+	Bio        string   `json:"bio,omitempty"`
+	QuoteCount int      `json:"quote_count"` // filled in by the store on reads
+}
+
+// Keys returns keys of the name and aliases of the author.
+func (ad AuthorData) Keys() []Author {
+	keys := make([]Author, 0, len(ad.Aliases)+1)
+	keys = append(keys, ad.Name.Key())
+	for _, alias := range ad.Aliases {
+		keys = append(keys, alias.Key())
+	}
+
+	return keys
+}
+
+func (ad AuthorData) Validate() error {
+	err := ad.Name.Validate()
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range ad.Aliases {
+		if len(alias.Key()) == 0 {
+			return fmt.Errorf("alias cannot be empty")
+		}
+	}
+
+	for _, year := range []int{ad.BirthYear, ad.DeathYear} {
+		if year < -maxAuthorYear || year > maxAuthorYear {
+			return fmt.Errorf("years should be between %d and %d", -maxAuthorYear, maxAuthorYear)
+		}
+	}
+
+	if ad.BirthYear != 0 && ad.DeathYear != 0 && ad.DeathYear < ad.BirthYear {
+		return fmt.Errorf("death year cannot be before birth year")
+	}
+
+	if utf8.RuneCountInString(ad.Bio) > maxBioLength {
+		return fmt.Errorf("bio cannot be longer than %d characters", maxBioLength)
+	}
+
+	return nil
+}
+
+// NormalizeAuthor trims the name and aliases of the author collapsing
+// whitespace and drops aliases matching the name or each other.
+func NormalizeAuthor(author AuthorData) AuthorData {
+	author.Name = Author(strings.Join(strings.Fields(string(author.Name)), " "))
+	author.Bio = strings.TrimSpace(author.Bio)
+
+	keys := map[Author]bool{author.Name.Key(): true}
+	aliases := make([]Author, 0, len(author.Aliases))
+	for _, alias := range author.Aliases {
+		alias = Author(strings.Join(strings.Fields(string(alias)), " "))
+		if keys[alias.Key()] {
+			continue
+		}
+		keys[alias.Key()] = true
+		aliases = append(aliases, alias)
+	}
+	author.Aliases = nil
+	if len(aliases) != 0 {
+		author.Aliases = aliases
+	}
+
+	return author
+}
+
+// PatchAuthorRequest follows JSON Merge Patch semantics like
+// PatchQuoteRequest: null removes aliases, years or bio, while the name cannot
+// be removed.
+type PatchAuthorRequest struct {
+	Name      *Author   `json:"name,omitempty"`
+	Aliases   *[]Author `json:"aliases,omitempty"`
+	BirthYear *int      `json:"birth_year,omitempty"`
+	DeathYear *int      `json:"death_year,omitempty"`
+	Bio       *string   `json:"bio,omitempty"`
+}
+
+func (par *PatchAuthorRequest) UnmarshalJSON(data []byte) error {
+	fields, err := decodePatchFields(data)
+	if err != nil {
+		return err
+	}
+
+	if fields.removes("name") {
+		return fmt.Errorf("name cannot be removed")
+	}
+
+	type plain PatchAuthorRequest
+	err = json.Unmarshal(data, (*plain)(par))
+	if err != nil {
+		return err
+	}
+
+	if fields.removes("aliases") {
+		par.Aliases = &[]Author{}
+	}
+	if fields.removes("birth_year") {
+		par.BirthYear = new(int)
+	}
+	if fields.removes("death_year") {
+		par.DeathYear = new(int)
+	}
+	if fields.removes("bio") {
+		par.Bio = new(string)
+	}
+
+	return nil
+}
+
+// Apply returns author with the patch applied.
+func (par PatchAuthorRequest) Apply(author AuthorData) AuthorData {
+	if par.Name != nil {
+		author.Name = *par.Name
+	}
+	if par.Aliases != nil {
+		author.Aliases = *par.Aliases
+	}
+	if par.BirthYear != nil {
+		author.BirthYear = *par.BirthYear
+	}
+	if par.DeathYear != nil {
+		author.DeathYear = *par.DeathYear
+	}
+	if par.Bio != nil {
+		author.Bio = *par.Bio
+	}
+
+	return author
+}
+
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 50
+)
+
+type SuggestAuthorsRequest struct {
+	Prefix Author
+	Match  AuthorMatch // AuthorMatchTranslit if empty
+	Limit  int         // DefaultSuggestLimit if zero
+}
+
+func (sar SuggestAuthorsRequest) Validate() error {
+	if len(sar.Prefix.Key()) == 0 {
+		return fmt.Errorf("prefix cannot be empty")
+	}
+
+	if sar.Limit < 0 || sar.Limit > MaxSuggestLimit {
+		return fmt.Errorf("limit should be between 1 and %d", MaxSuggestLimit)
+	}
+
+	return sar.Match.Validate()
+}
+
+// MergeAuthorsRequest merges an author into the author with id Into.
+type MergeAuthorsRequest struct {
+	Into  Id     `json:"into"`
+	Actor string `json:"-"` // taken from the X-Actor header
+}
+
+func (mar MergeAuthorsRequest) Validate() error {
+	err := validateActor(mar.Actor)
+	if err != nil {
+		return err
+	}
+
+	return mar.Into.Validate()
+}
+
+type GetAuthorsResponse struct {
+	Ok      bool         `json:"ok"`
+	Message string       `json:"message,omitempty"`
+	Authors []AuthorData `json:"authors"`
+}
+
+type SuggestAuthorsResponse struct {
+	Ok      bool         `json:"ok"`
+	Message string       `json:"message,omitempty"`
+	Authors []AuthorData `json:"authors"`
+}
+
+type GetAuthorResponse struct {
+	Ok      bool       `json:"ok"`
+	Message string     `json:"message,omitempty"`
+	Author  AuthorData `json:"author"`
+}
+
+type UpdateAuthorResponse struct {
+	Ok      bool       `json:"ok"`
+	Message string     `json:"message,omitempty"`
+	Author  AuthorData `json:"author"`
+}
@@ -0,0 +1,37 @@
+package types
+
+import "fmt"
+
+const (
+	// DefaultDuplicateThreshold is the least similarity of near-duplicates
+	DefaultDuplicateThreshold = 0.7
+	// MinDuplicateThreshold is the least threshold at which near-duplicates
+	// are found reliably
+	MinDuplicateThreshold = 0.5
+)
+
+// Duplicate is a quote with nearly the same text as another quote. Similarity
+// is an estimate of the share of common character sequences in the texts
+// compared up to punctuation, whitespace and case.
+type Duplicate struct {
+	Quote      QuoteData `json:"quote"`
+	Similarity float64   `json:"similarity"`
+}
+
+type GetDuplicatesRequest struct {
+	Threshold float64 // DefaultDuplicateThreshold if zero
+}
+
+func (gdr GetDuplicatesRequest) Validate() error {
+	if gdr.Threshold != 0 && !(gdr.Threshold >= MinDuplicateThreshold && gdr.Threshold <= 1) {
+		return fmt.Errorf("threshold should be between %v and 1", MinDuplicateThreshold)
+	}
+
+	return nil
+}
+
+type GetDuplicatesResponse struct {
+	Ok         bool        `json:"ok"`
+	Message    string      `json:"message,omitempty"`
+	Duplicates []Duplicate `json:"duplicates"`
+}
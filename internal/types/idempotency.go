@@ -0,0 +1,29 @@
+package types
+
+import "fmt"
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyKey is chosen by a client to identify a request, so retries of
+// the request are not processed twice.
+type IdempotencyKey string
+
+func (ik IdempotencyKey) Validate() error {
+	if len(ik) == 0 {
+		return fmt.Errorf("idempotency key cannot be empty")
+	}
+
+	if len(ik) > maxIdempotencyKeyLength {
+		return fmt.Errorf("idempotency key cannot be longer than %d bytes", maxIdempotencyKeyLength)
+	}
+
+	return nil
+}
+
+// StoredResponse is the response to a request with an idempotency key, which
+// is sent again for retries of the request.
+type StoredResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
@@ -0,0 +1,25 @@
+package types
+
+import "encoding/json"
+
+// patchFields holds the raw fields of a merge patch by their JSON names.
+// Decoding leaves a pointer field nil both when it is absent and when it is
+// null, but in a merge patch null removes the value while an absent field
+// keeps it, so patch requests decode their fields into patchFields first.
+type patchFields map[string]json.RawMessage
+
+func decodePatchFields(data []byte) (patchFields, error) {
+	fields := make(patchFields)
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// removes reports whether the patch sets the field to null. Patch requests
+// keep such fields as pointers to empty values, which are saved as removed.
+func (pf patchFields) removes(name string) bool {
+	value, ok := pf[name]
+	return ok && string(value) == "null"
+}
@@ -1,6 +1,19 @@
 package types
 
-import "fmt"
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/NikitaBogoslovskiy/quotes/internal/translit"
+)
 
 type Id uint64
 
@@ -15,13 +28,47 @@ func (id Id) Validate() error {
 type Author string
 
 func (a Author) Validate() error {
-	if len(a) == 0 {
+	if len(a.Key()) == 0 {
 		return fmt.Errorf("author cannot be empty")
 	}
 
 	return nil
 }
 
+// Key returns the canonical form of the author name used to match authors:
+// trimmed, with whitespace collapsed, case-folded and with "ё" replaced by
+// "е". Quotes keep the author name as it was written.
+func (a Author) Key() Author {
+	key := strings.ToLower(strings.Join(strings.Fields(string(a)), " "))
+	return Author(strings.ReplaceAll(key, "ё", "е"))
+}
+
+// TranslitKey returns the key of the name which is the same for its russian
+// and latin spellings, e.g. "Толстой" and "Tolstoy".
+func (a Author) TranslitKey() Author {
+	return Author(translit.Key(string(a.Key())))
+}
+
+// AuthorMatch is a way an author name given in a query matches names of
+// authors.
+type AuthorMatch string
+
+const (
+	// AuthorMatchExact matches names having the same Author.Key
+	AuthorMatchExact AuthorMatch = "exact"
+	// AuthorMatchTranslit also matches names written in another script
+	AuthorMatchTranslit AuthorMatch = "translit"
+)
+
+func (am AuthorMatch) Validate() error {
+	switch am {
+	case "", AuthorMatchExact, AuthorMatchTranslit:
+		return nil
+	default:
+		return fmt.Errorf("author match should be either exact or translit")
+	}
+}
+
 type Quote string
 
 func (q Quote) Validate() error {
@@ -32,19 +79,278 @@ func (q Quote) Validate() error {
 	return nil
 }
 
+// Key returns the canonical form of the text used to find duplicates: words of
+// letters and digits in lower case separated by single spaces, with "ё"
+// replaced by "е", so punctuation and whitespace do not matter.
+func (q Quote) Key() Quote {
+	words := strings.FieldsFunc(strings.ToLower(string(q)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return Quote(strings.ReplaceAll(strings.Join(words, " "), "ё", "е"))
+}
+
+const maxTagLength = 32
+
+// Tag is a lowercase word of letters and digits, possibly joined by hyphens.
+type Tag string
+
+func (t Tag) Validate() error {
+	if len(t) == 0 {
+		return fmt.Errorf("tag cannot be empty")
+	}
+
+	if utf8.RuneCountInString(string(t)) > maxTagLength {
+		return fmt.Errorf("tag cannot be longer than %d characters", maxTagLength)
+	}
+
+	prev := '-'
+	for _, r := range string(t) {
+		switch {
+		case r == '-' && prev == '-':
+			return fmt.Errorf("tag %q has misplaced hyphen", t)
+		case r != '-' && !unicode.IsDigit(r) && !(unicode.IsLetter(r) && !unicode.IsUpper(r)):
+			return fmt.Errorf("tag %q should contain only lowercase letters, digits and hyphens", t)
+		}
+		prev = r
+	}
+	if prev == '-' {
+		return fmt.Errorf("tag %q has misplaced hyphen", t)
+	}
+
+	return nil
+}
+
+// NormalizeTags lowercases tags, sorts them and removes duplicates.
+func NormalizeTags(tags []Tag) []Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	normalized := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		normalized = append(normalized, Tag(strings.ToLower(strings.TrimSpace(string(tag)))))
+	}
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
+
+func ValidateTags(tags []Tag) error {
+	for _, tag := range tags {
+		err := tag.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type TagCount struct {
+	Tag   Tag `json:"tag"`
+	Count int `json:"count"`
+}
+
+const (
+	maxSourceFieldLength = 256
+	maxSourceYear        = 9999
+)
+
+// Source is a publication a quote comes from. All fields are optional, years
+// before the Common Era are negative.
+type Source struct {
+	Title   string `json:"title,omitempty"`
+	Chapter string `json:"chapter,omitempty"`
+	Page    int    `json:"page,omitempty"`
+	Year    int    `json:"year,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+func (s Source) Validate() error {
+	if utf8.RuneCountInString(s.Title) > maxSourceFieldLength {
+		return fmt.Errorf("source title cannot be longer than %d characters", maxSourceFieldLength)
+	}
+
+	if utf8.RuneCountInString(s.Chapter) > maxSourceFieldLength {
+		return fmt.Errorf("source chapter cannot be longer than %d characters", maxSourceFieldLength)
+	}
+
+	if s.Page < 0 {
+		return fmt.Errorf("source page cannot be negative")
+	}
+
+	if s.Year < -maxSourceYear || s.Year > maxSourceYear {
+		return fmt.Errorf("source year should be between %d and %d", -maxSourceYear, maxSourceYear)
+	}
+
+	if s.URL != "" {
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("source url should be an absolute http or https url")
+		}
+	}
+
+	return nil
+}
+
+// NormalizeSource trims source fields and drops the source if it is empty.
+func NormalizeSource(source *Source) *Source {
+	if source == nil {
+		return nil
+	}
+
+	normalized := Source{
+		Title:   strings.TrimSpace(source.Title),
+		Chapter: strings.TrimSpace(source.Chapter),
+		Page:    source.Page,
+		Year:    source.Year,
+		URL:     strings.TrimSpace(source.URL),
+	}
+	if normalized == (Source{}) {
+		return nil
+	}
+
+	return &normalized
+}
+
+// PatchSourceRequest is merged into the source of a quote field by field:
+// absent fields are left unchanged and null removes a field.
+type PatchSourceRequest struct {
+	Title   *string `json:"title,omitempty"`
+	Chapter *string `json:"chapter,omitempty"`
+	Page    *int    `json:"page,omitempty"`
+	Year    *int    `json:"year,omitempty"`
+	URL     *string `json:"url,omitempty"`
+}
+
+// removeSource is the patch removing every field of a source.
+func removeSource() *PatchSourceRequest {
+	return &PatchSourceRequest{
+		Title:   new(string),
+		Chapter: new(string),
+		Page:    new(int),
+		Year:    new(int),
+		URL:     new(string),
+	}
+}
+
+func (psr *PatchSourceRequest) UnmarshalJSON(data []byte) error {
+	fields, err := decodePatchFields(data)
+	if err != nil {
+		return err
+	}
+
+	type plain PatchSourceRequest
+	err = json.Unmarshal(data, (*plain)(psr))
+	if err != nil {
+		return err
+	}
+
+	if fields.removes("title") {
+		psr.Title = new(string)
+	}
+	if fields.removes("chapter") {
+		psr.Chapter = new(string)
+	}
+	if fields.removes("page") {
+		psr.Page = new(int)
+	}
+	if fields.removes("year") {
+		psr.Year = new(int)
+	}
+	if fields.removes("url") {
+		psr.URL = new(string)
+	}
+
+	return nil
+}
+
+// Apply returns source with the patch applied, normalized by NormalizeSource.
+func (psr PatchSourceRequest) Apply(source *Source) *Source {
+	patched := Source{}
+	if source != nil {
+		patched = *source
+	}
+
+	if psr.Title != nil {
+		patched.Title = *psr.Title
+	}
+	if psr.Chapter != nil {
+		patched.Chapter = *psr.Chapter
+	}
+	if psr.Page != nil {
+		patched.Page = *psr.Page
+	}
+	if psr.Year != nil {
+		patched.Year = *psr.Year
+	}
+	if psr.URL != nil {
+		patched.URL = *psr.URL
+	}
+
+	return NormalizeSource(&patched)
+}
+
+// Attribution tells whether a quote is known to belong to its author. Empty
+// attribution means that nobody has checked it yet.
+type Attribution string
+
+const (
+	AttributionUnknown       Attribution = ""
+	AttributionVerified      Attribution = "verified"
+	AttributionDisputed      Attribution = "disputed"
+	AttributionMisattributed Attribution = "misattributed"
+)
+
+func (a Attribution) Validate() error {
+	switch a {
+	case AttributionUnknown, AttributionVerified, AttributionDisputed, AttributionMisattributed:
+		return nil
+	default:
+		return fmt.Errorf("attribution should be one of verified, disputed or misattributed")
+	}
+}
+
 type QuoteData struct {
-	Id     Id     `json:"id,omitempty"`
-	Author Author `json:"author,omitempty"`
-	Quote  Quote  `json:"quote,omitempty"`
+	Id          Id          `json:"id,omitempty"`
+	Author      Author      `json:"author,omitempty"`
+	Quote       Quote       `json:"quote,omitempty"`
+	Tags        []Tag       `json:"tags,omitempty"`
+	Source      *Source     `json:"source,omitempty"`
+	Attribution Attribution `json:"attribution,omitempty"`
+	AuthorId    Id          `json:"author_id,omitempty"` // assigned by the store
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	UpdatedBy   string      `json:"updated_by,omitempty"` // actor of the last change
+	Version     uint64      `json:"version,omitempty"`    // incremented by the store on every change
+	DeletedAt   *time.Time  `json:"deleted_at,omitempty"` // set for quotes in the trash
+}
+
+// Versions are versions of a quote expected by a conditional request.
+type Versions []uint64
+
+// Match reports whether version is one of the versions. Any version matches
+// empty versions.
+func (v Versions) Match(version uint64) bool {
+	return len(v) == 0 || slices.Contains(v, version)
 }
 
 type CreateQuoteRequest struct {
-	Author Author `json:"author"`
-	Quote  Quote  `json:"quote"`
+	Author      Author      `json:"author"`
+	Quote       Quote       `json:"quote"`
+	Tags        []Tag       `json:"tags,omitempty"`
+	Source      *Source     `json:"source,omitempty"`
+	Attribution Attribution `json:"attribution,omitempty"`
+	Actor       string      `json:"-"` // taken from the X-Actor header
 }
 
 func (cqr CreateQuoteRequest) Validate() error {
-	err := cqr.Author.Validate()
+	err := validateActor(cqr.Actor)
+	if err != nil {
+		return err
+	}
+
+	err = cqr.Author.Validate()
 	if err != nil {
 		return err
 	}
@@ -54,9 +360,390 @@ func (cqr CreateQuoteRequest) Validate() error {
 		return err
 	}
 
+	return validateCitation(cqr.Tags, cqr.Source, cqr.Attribution)
+}
+
+type UpdateQuoteRequest struct {
+	Author      Author      `json:"author"`
+	Quote       Quote       `json:"quote"`
+	Tags        []Tag       `json:"tags"`
+	Source      *Source     `json:"source"`
+	Attribution Attribution `json:"attribution"`
+	Actor       string      `json:"-"` // taken from the X-Actor header
+	Versions    Versions    `json:"-"` // expected versions of the quote
+}
+
+func (uqr UpdateQuoteRequest) Validate() error {
+	err := validateActor(uqr.Actor)
+	if err != nil {
+		return err
+	}
+
+	err = uqr.Author.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = uqr.Quote.Validate()
+	if err != nil {
+		return err
+	}
+
+	return validateCitation(uqr.Tags, uqr.Source, uqr.Attribution)
+}
+
+// validateCitation validates optional fields shared by create and update
+// requests.
+func validateCitation(tags []Tag, source *Source, attribution Attribution) error {
+	err := ValidateTags(tags)
+	if err != nil {
+		return err
+	}
+
+	if source != nil {
+		err = source.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return attribution.Validate()
+}
+
+// PatchQuoteRequest follows JSON Merge Patch semantics: absent fields are left
+// unchanged, null removes tags, source or attribution. Fields of the source are
+// merged one by one (see PatchSourceRequest). Author and quote cannot be
+// removed, so null values are rejected for them.
+type PatchQuoteRequest struct {
+	Author      *Author             `json:"author,omitempty"`
+	Quote       *Quote              `json:"quote,omitempty"`
+	Tags        *[]Tag              `json:"tags,omitempty"`
+	Source      *PatchSourceRequest `json:"source,omitempty"`
+	Attribution *Attribution        `json:"attribution,omitempty"`
+	Actor       string              `json:"-"` // taken from the X-Actor header
+	Versions    Versions            `json:"-"` // expected versions of the quote
+}
+
+func (pqr *PatchQuoteRequest) UnmarshalJSON(data []byte) error {
+	fields, err := decodePatchFields(data)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{"author", "quote"} {
+		if fields.removes(name) {
+			return fmt.Errorf("%s cannot be removed", name)
+		}
+	}
+
+	type plain PatchQuoteRequest
+	err = json.Unmarshal(data, (*plain)(pqr))
+	if err != nil {
+		return err
+	}
+
+	if fields.removes("tags") {
+		pqr.Tags = &[]Tag{}
+	}
+	if fields.removes("source") {
+		pqr.Source = removeSource()
+	}
+	if fields.removes("attribution") {
+		attribution := AttributionUnknown
+		pqr.Attribution = &attribution
+	}
+
 	return nil
 }
 
+func (pqr PatchQuoteRequest) Validate() error {
+	err := validateActor(pqr.Actor)
+	if err != nil {
+		return err
+	}
+
+	if pqr.Author != nil {
+		err := pqr.Author.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if pqr.Quote != nil {
+		err := pqr.Quote.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if pqr.Tags != nil {
+		err := ValidateTags(*pqr.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	if pqr.Source != nil {
+		// fields of the stored source are valid already
+		source := pqr.Source.Apply(nil)
+		if source != nil {
+			err := source.Validate()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if pqr.Attribution != nil {
+		return pqr.Attribution.Validate()
+	}
+
+	return nil
+}
+
+type DeleteQuoteRequest struct {
+	Actor    string   // taken from the X-Actor header
+	Versions Versions // expected versions of the quote
+}
+
+func (dqr DeleteQuoteRequest) Validate() error {
+	return validateActor(dqr.Actor)
+}
+
+type RestoreQuoteRequest struct {
+	Actor string // taken from the X-Actor header
+}
+
+func (rqr RestoreQuoteRequest) Validate() error {
+	return validateActor(rqr.Actor)
+}
+
+const MaxPageLimit = 1000
+
+type SortField string
+
+const (
+	SortById      SortField = "id"
+	SortByAuthor  SortField = "author"
+	SortByCreated SortField = "created"
+	SortByLength  SortField = "length"
+)
+
+// Sort is an order of quotes in a listing. Quotes with equal sort keys are
+// ordered by id, so the order is always deterministic.
+type Sort struct {
+	Field SortField
+	Desc  bool
+}
+
+// ParseSort parses a field name optionally prefixed by "-" for descending
+// order. Empty string means ascending order by id.
+func ParseSort(s string) (Sort, error) {
+	sort := Sort{Field: SortById}
+	if s == "" {
+		return sort, nil
+	}
+
+	if s[0] == '-' {
+		sort.Desc = true
+		s = s[1:]
+	}
+
+	switch field := SortField(s); field {
+	case SortById, SortByAuthor, SortByCreated, SortByLength:
+		sort.Field = field
+	default:
+		return sort, fmt.Errorf("sort should be one of id, author, created, length optionally prefixed by -")
+	}
+
+	return sort, nil
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + string(s.Field)
+	}
+
+	return string(s.Field)
+}
+
+// Compare orders positions of quotes in a listing sorted by s.
+func (s Sort) Compare(a, b Cursor) int {
+	result := 0
+	switch s.Field {
+	case SortByAuthor:
+		result = strings.Compare(string(a.Author), string(b.Author))
+	case SortByCreated:
+		result = cmp.Compare(a.Created, b.Created)
+	case SortByLength:
+		result = cmp.Compare(a.Length, b.Length)
+	}
+
+	if result == 0 {
+		result = cmp.Compare(a.Id, b.Id)
+	}
+
+	if s.Desc {
+		return -result
+	}
+
+	return result
+}
+
+// Cursor is a position of a quote in a listing sorted by Sort. The next page
+// starts right after it, even if the quote has been deleted since.
+type Cursor struct {
+	Sort    string `json:"sort,omitempty"`
+	Id      Id     `json:"id"`
+	Author  Author `json:"author,omitempty"`  // Author.Key of the name
+	Created int64  `json:"created,omitempty"` // Unix time in nanoseconds
+	Length  int    `json:"length,omitempty"`
+}
+
+func NewCursor(sort Sort, quote QuoteData) Cursor {
+	c := Cursor{Sort: sort.String(), Id: quote.Id}
+	switch sort.Field {
+	case SortByAuthor:
+		// differently written names of an author are sorted together
+		c.Author = quote.Author.Key()
+	case SortByCreated:
+		c.Created = quote.CreatedAt.UnixNano()
+	case SortByLength:
+		c.Length = utf8.RuneCountInString(string(quote.Quote))
+	}
+
+	return c
+}
+
+func (c Cursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func ParseCursor(s string) (Cursor, error) {
+	c := Cursor{}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("cursor is malformed")
+	}
+
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return c, fmt.Errorf("cursor is malformed")
+	}
+
+	return c, nil
+}
+
+type QuotesQuery struct {
+	Author        Author
+	AuthorMatch   AuthorMatch // AuthorMatchExact if empty
+	AuthorId      Id          // no filter if zero
+	Tags          []Tag       // quotes having all of the tags
+	AnyTag        bool        // quotes having any of the tags instead
+	Attribution   Attribution
+	Verified      *bool     // no filter if nil
+	CreatedAfter  time.Time // no bound if zero
+	CreatedBefore time.Time // no bound if zero
+	Sort          Sort
+	After         *Cursor
+	Limit         int // no limit if zero
+}
+
+// Matches checks filters of the query which are not backed by store indexes.
+func (qq QuotesQuery) Matches(quote QuoteData) bool {
+	if qq.Attribution != AttributionUnknown && quote.Attribution != qq.Attribution {
+		return false
+	}
+
+	if qq.Verified != nil && (quote.Attribution == AttributionVerified) != *qq.Verified {
+		return false
+	}
+
+	if !qq.CreatedAfter.IsZero() && !quote.CreatedAt.After(qq.CreatedAfter) {
+		return false
+	}
+
+	if !qq.CreatedBefore.IsZero() && !quote.CreatedAt.Before(qq.CreatedBefore) {
+		return false
+	}
+
+	return true
+}
+
+type GetQuotesRequest struct {
+	Author        Author
+	AuthorMatch   AuthorMatch // AuthorMatchTranslit if empty
+	AuthorId      Id
+	Tags          []Tag
+	TagMode       string // all or any
+	Attribution   Attribution
+	Verified      *bool
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Sort          string
+	Limit         int // all quotes are returned if zero
+	Cursor        string
+}
+
+func (gqr GetQuotesRequest) Validate() error {
+	if gqr.Limit < 0 || gqr.Limit > MaxPageLimit {
+		return fmt.Errorf("limit should be between 1 and %d", MaxPageLimit)
+	}
+
+	if gqr.TagMode != "" && gqr.TagMode != "all" && gqr.TagMode != "any" {
+		return fmt.Errorf("tag mode should be either all or any")
+	}
+
+	err := gqr.AuthorMatch.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = gqr.Attribution.Validate()
+	if err != nil {
+		return err
+	}
+
+	return ValidateTags(gqr.Tags)
+}
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+type SearchQuotesRequest struct {
+	Query string
+	Limit int // DefaultSearchLimit if zero
+}
+
+func (sqr SearchQuotesRequest) Validate() error {
+	if strings.TrimSpace(sqr.Query) == "" {
+		return fmt.Errorf("search query cannot be empty")
+	}
+
+	if sqr.Limit < 0 || sqr.Limit > MaxSearchLimit {
+		return fmt.Errorf("limit should be between 1 and %d", MaxSearchLimit)
+	}
+
+	return nil
+}
+
+// SearchHit is a quote found by a search query. Snippet is an HTML fragment of
+// the quote with matched words wrapped into <mark> tags.
+type SearchHit struct {
+	Quote   QuoteData `json:"quote"`
+	Score   float64   `json:"score"`
+	Snippet string    `json:"snippet"`
+}
+
+type QuotesPage struct {
+	Quotes     []QuoteData
+	NextCursor string // empty for the last page
+}
+
 type CreateQuoteResponse struct {
 	Ok      bool   `json:"ok"`
 	Message string `json:"message,omitempty"`
@@ -64,9 +751,16 @@ type CreateQuoteResponse struct {
 }
 
 type GetQuotesResponse struct {
+	Ok         bool        `json:"ok"`
+	Message    string      `json:"message,omitempty"`
+	Quotes     []QuoteData `json:"quotes"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+type SearchQuotesResponse struct {
 	Ok      bool        `json:"ok"`
 	Message string      `json:"message,omitempty"`
-	Quotes  []QuoteData `json:"quotes"`
+	Results []SearchHit `json:"results"`
 }
 
 type GetRandomQuoteResponse struct {
@@ -75,7 +769,79 @@ type GetRandomQuoteResponse struct {
 	Quote   QuoteData `json:"quote,omitempty"`
 }
 
+type GetQuoteResponse struct {
+	Ok      bool      `json:"ok"`
+	Message string    `json:"message,omitempty"`
+	Quote   QuoteData `json:"quote,omitempty"`
+}
+
+type UpdateQuoteResponse struct {
+	Ok      bool      `json:"ok"`
+	Message string    `json:"message,omitempty"`
+	Quote   QuoteData `json:"quote,omitempty"`
+}
+
+type GetTagsResponse struct {
+	Ok      bool       `json:"ok"`
+	Message string     `json:"message,omitempty"`
+	Tags    []TagCount `json:"tags"`
+}
+
 type DeleteQuoteResponse struct {
 	Ok      bool   `json:"ok"`
 	Message string `json:"message,omitempty"`
 }
+
+type GetTrashResponse struct {
+	Ok      bool        `json:"ok"`
+	Message string      `json:"message,omitempty"`
+	Quotes  []QuoteData `json:"quotes"`
+}
+
+type RestoreQuoteResponse struct {
+	Ok      bool      `json:"ok"`
+	Message string    `json:"message,omitempty"`
+	Quote   QuoteData `json:"quote,omitempty"`
+}
+
+type PurgeTrashResponse struct {
+	Ok      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+	Purged  int    `json:"purged"`
+}
+
+type ErrorCode string
+
+const (
+	ErrorCodeMalformedRequest     ErrorCode = "malformed_request"
+	ErrorCodeInvalidInput         ErrorCode = "invalid_input"
+	ErrorCodeNotFound             ErrorCode = "not_found"
+	ErrorCodeConflict             ErrorCode = "conflict"
+	ErrorCodeDuplicate            ErrorCode = "duplicate"
+	ErrorCodeVersionMismatch      ErrorCode = "version_mismatch"
+	ErrorCodeIdempotencyKeyReused ErrorCode = "idempotency_key_reused"
+	ErrorCodeRequestInProgress    ErrorCode = "request_in_progress"
+	ErrorCodeCapacityExceeded     ErrorCode = "capacity_exceeded"
+	ErrorCodeInternal             ErrorCode = "internal_error"
+)
+
+// ErrorResponse is returned by every endpoint on failure.
+type ErrorResponse struct {
+	Ok      bool      `json:"ok"`
+	Message string    `json:"message"`
+	Code    ErrorCode `json:"code"`
+	// ExistingId is the id of the quote duplicated by a created one.
+	ExistingId Id `json:"existing_id,omitempty"`
+}
+
+// Problem is an RFC 7807 problem details object, returned on failure instead
+// of ErrorResponse to clients accepting application/problem+json.
+type Problem struct {
+	Type   string    `json:"type"`
+	Title  string    `json:"title"`
+	Status int       `json:"status"`
+	Detail string    `json:"detail,omitempty"`
+	Code   ErrorCode `json:"code"`
+	// ExistingId is the id of the quote duplicated by a created one.
+	ExistingId Id `json:"existing_id,omitempty"`
+}
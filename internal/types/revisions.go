@@ -0,0 +1,151 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxActorLength = 64
+
+// validateActor checks the name of whoever makes a change. Empty actor means
+// that the change is anonymous.
+func validateActor(actor string) error {
+	if utf8.RuneCountInString(actor) > maxActorLength {
+		return fmt.Errorf("actor cannot be longer than %d characters", maxActorLength)
+	}
+
+	if strings.TrimSpace(actor) != actor {
+		return fmt.Errorf("actor cannot start or end with whitespace")
+	}
+
+	return nil
+}
+
+// Revision is a version of the content of a quote. The store saves a revision
+// on every change of the content, numbering them from 1.
+type Revision struct {
+	QuoteId     Id          `json:"quote_id"`
+	Number      int         `json:"number"`
+	Author      Author      `json:"author"`
+	Quote       Quote       `json:"quote"`
+	Tags        []Tag       `json:"tags,omitempty"`
+	Source      *Source     `json:"source,omitempty"`
+	Attribution Attribution `json:"attribution,omitempty"`
+	Actor       string      `json:"actor,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// NewRevision returns the revision with specified number holding the content
+// of quote as of its last update.
+func NewRevision(quote QuoteData, number int) Revision {
+	return Revision{
+		QuoteId:     quote.Id,
+		Number:      number,
+		Author:      quote.Author,
+		Quote:       quote.Quote,
+		Tags:        quote.Tags,
+		Source:      quote.Source,
+		Attribution: quote.Attribution,
+		Actor:       quote.UpdatedBy,
+		CreatedAt:   quote.UpdatedAt,
+	}
+}
+
+// Apply returns quote with the content of the revision.
+func (r Revision) Apply(quote QuoteData) QuoteData {
+	quote.Author = r.Author
+	quote.Quote = r.Quote
+	quote.Tags = slices.Clone(r.Tags)
+	quote.Source = r.Source
+	quote.Attribution = r.Attribution
+	return quote
+}
+
+// Diff returns fields whose values differ in the revision and other.
+func (r Revision) Diff(other Revision) []FieldChange {
+	changes := make([]FieldChange, 0)
+	if r.Author != other.Author {
+		changes = append(changes, FieldChange{Field: "author", From: r.Author, To: other.Author})
+	}
+	if r.Quote != other.Quote {
+		changes = append(changes, FieldChange{Field: "quote", From: r.Quote, To: other.Quote})
+	}
+	if !slices.Equal(r.Tags, other.Tags) {
+		changes = append(changes, FieldChange{Field: "tags", From: r.Tags, To: other.Tags})
+	}
+	if !equalSources(r.Source, other.Source) {
+		changes = append(changes, FieldChange{Field: "source", From: r.Source, To: other.Source})
+	}
+	if r.Attribution != other.Attribution {
+		changes = append(changes, FieldChange{Field: "attribution", From: r.Attribution, To: other.Attribution})
+	}
+
+	return changes
+}
+
+func equalSources(s1, s2 *Source) bool {
+	if s1 == nil || s2 == nil {
+		return s1 == s2
+	}
+
+	return *s1 == *s2
+}
+
+// FieldChange is a field of a quote changed between two revisions. Removed
+// tags, source or attribution are null or empty in To.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type RevisionDiff struct {
+	QuoteId Id            `json:"quote_id"`
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// DiffRevisionsRequest compares revision From with revision To. Zero To means
+// the latest revision and zero From means the revision before To.
+type DiffRevisionsRequest struct {
+	From int
+	To   int
+}
+
+func (drr DiffRevisionsRequest) Validate() error {
+	if drr.From < 0 || drr.To < 0 {
+		return fmt.Errorf("revision should be a positive number")
+	}
+
+	return nil
+}
+
+// RevertQuoteRequest restores the content of revision To as a new revision.
+type RevertQuoteRequest struct {
+	To    int
+	Actor string
+}
+
+func (rqr RevertQuoteRequest) Validate() error {
+	if rqr.To <= 0 {
+		return fmt.Errorf("revision should be a positive number")
+	}
+
+	return validateActor(rqr.Actor)
+}
+
+type GetRevisionsResponse struct {
+	Ok        bool       `json:"ok"`
+	Message   string     `json:"message,omitempty"`
+	Revisions []Revision `json:"revisions"`
+}
+
+type DiffRevisionsResponse struct {
+	Ok      bool         `json:"ok"`
+	Message string       `json:"message,omitempty"`
+	Diff    RevisionDiff `json:"diff"`
+}